@@ -1,6 +1,10 @@
 package metrics
 
-import "sync/atomic"
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
 
 // Counters hold an int64 value that can be incremented and decremented.
 type Counter interface {
@@ -25,7 +29,7 @@ func NewCounter() Counter {
 	if UseNilMetrics {
 		return NilCounter{}
 	}
-	return &StandardCounter{0}
+	return &StandardCounter{}
 }
 
 // NewRegisteredCounter constructs and registers a new StandardCounter.
@@ -38,6 +42,31 @@ func NewRegisteredCounter(name string, r Registry) Counter {
 	return c
 }
 
+// RateOf returns a function which computes the per-second rate at which c
+// changed between successive calls, a lightweight alternative to a Meter.
+// Calls less than over apart return the previous rate rather than one
+// computed over a very short interval.  The first call measures from the
+// time RateOf was called.
+func RateOf(c Counter, over time.Duration) func() float64 {
+	return rateOf(c, over, time.Now)
+}
+
+func rateOf(c Counter, over time.Duration, now func() time.Time) func() float64 {
+	var mutex sync.Mutex
+	count, ts, rate := c.Count(), now(), 0.0
+	return func() float64 {
+		mutex.Lock()
+		defer mutex.Unlock()
+		t := now()
+		if elapsed := t.Sub(ts); elapsed >= over && 0 < elapsed {
+			n := c.Count()
+			rate = float64(n-count) / elapsed.Seconds()
+			count, ts = n, t
+		}
+		return rate
+	}
+}
+
 // CounterSnapshot is a read-only copy of another Counter.
 type CounterSnapshot int64
 
@@ -81,29 +110,32 @@ func (NilCounter) Inc(i int64) {}
 func (NilCounter) Snapshot() Counter { return NilCounter{} }
 
 // StandardCounter is the standard implementation of a Counter and uses the
-// sync/atomic package to manage a single int64 value.
+// sync/atomic package to manage a single int64 value.  atomic.Int64 is 64-bit
+// aligned wherever it sits, so a StandardCounter may be embedded anywhere in
+// another struct, even on 32-bit platforms like ARM and x86-32.  Its zero
+// value is ready to use.
 type StandardCounter struct {
-	count int64
+	count atomic.Int64
 }
 
 // Clear sets the counter to zero.
 func (c *StandardCounter) Clear() {
-	atomic.StoreInt64(&c.count, 0)
+	c.count.Store(0)
 }
 
 // Count returns the current count.
 func (c *StandardCounter) Count() int64 {
-	return atomic.LoadInt64(&c.count)
+	return c.count.Load()
 }
 
 // Dec decrements the counter by the given amount.
 func (c *StandardCounter) Dec(i int64) {
-	atomic.AddInt64(&c.count, -i)
+	c.count.Add(-i)
 }
 
 // Inc increments the counter by the given amount.
 func (c *StandardCounter) Inc(i int64) {
-	atomic.AddInt64(&c.count, i)
+	c.count.Add(i)
 }
 
 // Snapshot returns a read-only copy of the counter.
@@ -1,6 +1,9 @@
 package metrics
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func BenchmarkCounter(b *testing.B) {
 	c := NewCounter()
@@ -10,6 +13,31 @@ func BenchmarkCounter(b *testing.B) {
 	}
 }
 
+func TestAtomicEmbedded(t *testing.T) {
+	// 64-bit atomic operations panic on 32-bit platforms unless their operand
+	// is 64-bit aligned, which a preceding 32-bit field would otherwise break.
+	var s struct {
+		id       int32
+		counter  StandardCounter
+		id2      int32
+		gauge    StandardGauge
+		id3      int32
+		computed ComputedGauge
+		id4      int32
+		inFlight InFlightHistogram
+		id5      int32
+		async    StandardAsyncHistogram
+	}
+	s.counter.Inc(1)
+	s.gauge.Update(1)
+	s.computed.value.Store(1)
+	s.inFlight.inFlight.Add(1)
+	s.async.dropped.Add(1)
+	if 1 != s.counter.Count() || 1 != s.gauge.Value() || 1 != s.computed.Value() || 1 != s.inFlight.InFlight() || 1 != s.async.Dropped() {
+		t.Fatal(s.counter.Count(), s.gauge.Value(), s.computed.Value(), s.inFlight.InFlight(), s.async.Dropped())
+	}
+}
+
 func TestCounterClear(t *testing.T) {
 	c := NewCounter()
 	c.Inc(1)
@@ -68,6 +96,42 @@ func TestCounterZero(t *testing.T) {
 	}
 }
 
+func TestCounterZeroValue(t *testing.T) {
+	var c StandardCounter
+	c.Inc(47)
+	if count := c.Count(); 47 != count {
+		t.Errorf("c.Count(): 47 != %v\n", count)
+	}
+}
+
+func TestRateOf(t *testing.T) {
+	c := NewCounter()
+	now := time.Unix(0, 0)
+	rate := rateOf(c, 10*time.Second, func() time.Time { return now })
+
+	c.Inc(50)
+	now = now.Add(10 * time.Second)
+	if r := rate(); 5.0 != r {
+		t.Errorf("rate(): 5.0 != %v\n", r)
+	}
+
+	c.Inc(100)
+	now = now.Add(5 * time.Second)
+	if r := rate(); 5.0 != r {
+		t.Errorf("rate() before over elapsed: 5.0 != %v\n", r)
+	}
+	now = now.Add(15 * time.Second)
+	if r := rate(); 5.0 != r {
+		t.Errorf("rate(): 5.0 != %v\n", r)
+	}
+
+	c.Dec(40)
+	now = now.Add(20 * time.Second)
+	if r := rate(); -2.0 != r {
+		t.Errorf("rate(): -2.0 != %v\n", r)
+	}
+}
+
 func TestGetOrRegisterCounter(t *testing.T) {
 	r := NewRegistry()
 	NewRegisteredCounter("foo", r).Inc(47)
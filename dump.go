@@ -0,0 +1,63 @@
+package metrics
+
+import "fmt"
+
+// Dump returns a flattened view of every metric in r, keyed by metric name
+// and field, e.g. "latency.p99", suitable for a single debug log line.
+func Dump(r Registry) map[string]string {
+	data := make(map[string]string)
+	d := func(name, field, format string, v interface{}) {
+		data[name+"."+field] = fmt.Sprintf(format, v)
+	}
+	r.Each(func(name string, i interface{}) {
+		switch metric := i.(type) {
+		case Counter:
+			d(name, "count", "%d", metric.Count())
+		case Gauge:
+			d(name, "value", "%d", metric.Value())
+		case GaugeFloat64:
+			d(name, "value", "%f", metric.Value())
+		case Healthcheck:
+			metric.Check()
+			d(name, "error", "%v", metric.Error())
+		case Histogram:
+			h := metric.Snapshot()
+			ps := h.Percentiles([]float64{0.5, 0.75, 0.95, 0.99, 0.999})
+			d(name, "count", "%d", h.Count())
+			d(name, "min", "%d", h.Min())
+			d(name, "max", "%d", h.Max())
+			d(name, "mean", "%.2f", h.Mean())
+			d(name, "stddev", "%.2f", h.StdDev())
+			d(name, "p50", "%.2f", ps[0])
+			d(name, "p75", "%.2f", ps[1])
+			d(name, "p95", "%.2f", ps[2])
+			d(name, "p99", "%.2f", ps[3])
+			d(name, "p999", "%.2f", ps[4])
+		case Meter:
+			m := metric.Snapshot()
+			d(name, "count", "%d", m.Count())
+			d(name, "rate1", "%.2f", m.Rate1())
+			d(name, "rate5", "%.2f", m.Rate5())
+			d(name, "rate15", "%.2f", m.Rate15())
+			d(name, "ratemean", "%.2f", m.RateMean())
+		case Timer:
+			t := metric.Snapshot()
+			ps := t.Percentiles([]float64{0.5, 0.75, 0.95, 0.99, 0.999})
+			d(name, "count", "%d", t.Count())
+			d(name, "min", "%d", t.Min())
+			d(name, "max", "%d", t.Max())
+			d(name, "mean", "%.2f", t.Mean())
+			d(name, "stddev", "%.2f", t.StdDev())
+			d(name, "p50", "%.2f", ps[0])
+			d(name, "p75", "%.2f", ps[1])
+			d(name, "p95", "%.2f", ps[2])
+			d(name, "p99", "%.2f", ps[3])
+			d(name, "p999", "%.2f", ps[4])
+			d(name, "rate1", "%.2f", t.Rate1())
+			d(name, "rate5", "%.2f", t.Rate5())
+			d(name, "rate15", "%.2f", t.Rate15())
+			d(name, "ratemean", "%.2f", t.RateMean())
+		}
+	})
+	return data
+}
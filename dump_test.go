@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	r := NewRegistry()
+	NewRegisteredCounter("counter", r).Inc(47)
+	NewRegisteredGauge("gauge", r).Update(-3)
+	NewRegisteredGaugeFloat64("gaugefloat64", r).Update(1.5)
+	r.Register("healthcheck", NewHealthcheck(func(h Healthcheck) {
+		h.Unhealthy(errors.New("down"))
+	}))
+	h := NewRegisteredHistogram("histogram", r, NewUniformSample(100))
+	h.Update(1)
+	h.Update(3)
+	expected := map[string]string{
+		"counter.count":      "47",
+		"gauge.value":        "-3",
+		"gaugefloat64.value": "1.500000",
+		"healthcheck.error":  "down",
+		"histogram.count":    "2",
+		"histogram.min":      "1",
+		"histogram.max":      "3",
+		"histogram.mean":     "2.00",
+		"histogram.stddev":   "1.00",
+		"histogram.p50":      "2.00",
+		"histogram.p75":      "3.00",
+		"histogram.p95":      "3.00",
+		"histogram.p99":      "3.00",
+		"histogram.p999":     "3.00",
+	}
+	if data := Dump(r); !reflect.DeepEqual(expected, data) {
+		t.Fatal(data)
+	}
+}
+
+func TestDumpTimer(t *testing.T) {
+	r := NewRegistry()
+	NewRegisteredTimer("timer", r).Update(47)
+	data := Dump(r)
+	if 14 != len(data) {
+		t.Fatal(data)
+	}
+	if "47" != data["timer.max"] || "47.00" != data["timer.p99"] {
+		t.Fatal(data)
+	}
+	if _, ok := data["timer.rate1"]; !ok {
+		t.Fatal(data)
+	}
+}
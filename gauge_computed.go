@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// NewComputedGauge constructs a new ComputedGauge which calls f every d
+// duration and launches a goroutine.  Call Stop to end the goroutine.  When
+// UseNilMetrics is set the gauge is never computed and reads zero.
+func NewComputedGauge(d time.Duration, f func() int64) *ComputedGauge {
+	return NewComputedGaugeWithLifecycle(d, f, nil)
+}
+
+// NewComputedGaugeWithLifecycle constructs a new ComputedGauge just like
+// NewComputedGauge but tracks its goroutine with l, if l is not nil.
+func NewComputedGaugeWithLifecycle(d time.Duration, f func() int64, l *Lifecycle) *ComputedGauge {
+	if UseNilMetrics {
+		return &ComputedGauge{done: make(chan struct{})}
+	}
+	g := newComputedGauge(f)
+	startTicking(d, l, g.done, g.compute)
+	return g
+}
+
+// ComputedGauge is a Gauge whose value is computed by a function on an
+// interval and cached between computations, so expensive functions are
+// not called on every read.
+type ComputedGauge struct {
+	value atomic.Int64
+	f     func() int64
+	done  chan struct{}
+}
+
+func newComputedGauge(f func() int64) *ComputedGauge {
+	g := &ComputedGauge{f: f, done: make(chan struct{})}
+	g.compute()
+	return g
+}
+
+// Snapshot returns a read-only copy of the gauge.
+func (g *ComputedGauge) Snapshot() Gauge {
+	return GaugeSnapshot(g.Value())
+}
+
+// Stop ends the goroutine recomputing the gauge.  It must be called at most
+// once.
+func (g *ComputedGauge) Stop() {
+	close(g.done)
+}
+
+// Update panics.
+func (*ComputedGauge) Update(int64) {
+	panic("Update called on a ComputedGauge")
+}
+
+// Value returns the value as of the most recent computation.
+func (g *ComputedGauge) Value() int64 {
+	return g.value.Load()
+}
+
+func (g *ComputedGauge) compute() {
+	g.value.Store(g.f())
+}
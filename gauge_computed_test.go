@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputedGauge(t *testing.T) {
+	calls := int64(0)
+	g := newComputedGauge(func() int64 {
+		calls++
+		return calls * 10
+	})
+	if v := g.Value(); 10 != v {
+		t.Fatalf("g.Value(): 10 != %v\n", v)
+	}
+	if v := g.Value(); 10 != v {
+		t.Fatalf("g.Value() recomputed on read: 10 != %v\n", v)
+	}
+	runTicking(ticks(2), g.done, nil, g.compute)
+	if v := g.Value(); 30 != v {
+		t.Fatalf("g.Value(): 30 != %v\n", v)
+	}
+	if snapshot := g.Snapshot(); 30 != snapshot.Value() {
+		t.Fatal(snapshot)
+	}
+}
+
+func TestComputedGaugeStop(t *testing.T) {
+	g := newComputedGauge(func() int64 { return 0 })
+	stopped := make(chan struct{})
+	go func() {
+		runTicking(make(chan time.Time), g.done, nil, g.compute)
+		close(stopped)
+	}()
+	g.Stop()
+	<-stopped
+}
+
+// ticks returns a closed channel holding n ticks, standing in for a
+// time.Ticker so that a run loop processes exactly n ticks and returns.
+func ticks(n int) <-chan time.Time {
+	c := make(chan time.Time, n)
+	for i := 0; i < n; i++ {
+		c <- time.Time{}
+	}
+	close(c)
+	return c
+}
+
+func TestComputedGaugeNil(t *testing.T) {
+	UseNilMetrics = true
+	defer func() { UseNilMetrics = false }()
+	called := false
+	g := NewComputedGauge(time.Hour, func() int64 { called = true; return 47 })
+	if v := g.Value(); 0 != v {
+		t.Errorf("g.Value(): 0 != %v\n", v)
+	}
+	if called {
+		t.Error("f called under UseNilMetrics")
+	}
+	g.Stop()
+}
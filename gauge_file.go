@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"io/ioutil"
+	"sync"
+	"time"
+)
+
+// NewFileGauge constructs a new FileGauge which reads the file at path with
+// parse, re-reading it at most once per ttl.  This suits values the kernel
+// exposes as files, such as cgroup memory usage and limits.
+func NewFileGauge(path string, parse func([]byte) (int64, error), ttl time.Duration) Gauge {
+	if UseNilMetrics {
+		return NilGauge{}
+	}
+	return &FileGauge{
+		path:  path,
+		parse: parse,
+		ttl:   ttl,
+		now:   time.Now,
+	}
+}
+
+// FileGauge is a Gauge whose value is parsed from a file and cached.  If the
+// file can't be read or parsed the last good value is kept and the error is
+// counted.
+type FileGauge struct {
+	mutex  sync.Mutex
+	path   string
+	parse  func([]byte) (int64, error)
+	ttl    time.Duration
+	value  int64
+	errors int64
+	readAt time.Time
+	now    func() time.Time
+}
+
+// Errors returns the number of failed reads or parses.
+func (g *FileGauge) Errors() int64 {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.errors
+}
+
+// Snapshot returns a read-only copy of the gauge.
+func (g *FileGauge) Snapshot() Gauge {
+	return GaugeSnapshot(g.Value())
+}
+
+// Update panics.
+func (*FileGauge) Update(int64) {
+	panic("Update called on a FileGauge")
+}
+
+// Value returns the value parsed from the file, re-reading it if the cached
+// value is older than the gauge's ttl.
+func (g *FileGauge) Value() int64 {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	now := g.now()
+	if g.readAt.IsZero() || now.Sub(g.readAt) >= g.ttl {
+		g.readAt = now
+		if v, err := g.read(); nil != err {
+			g.errors++
+		} else {
+			g.value = v
+		}
+	}
+	return g.value
+}
+
+func (g *FileGauge) read() (int64, error) {
+	b, err := ioutil.ReadFile(g.path)
+	if nil != err {
+		return 0, err
+	}
+	return g.parse(b)
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func parseInt64(b []byte) (int64, error) {
+	return strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+}
+
+func TestFileGauge(t *testing.T) {
+	f, err := ioutil.TempFile("", "gauge_file_test")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.Close()
+	write := func(s string) {
+		if err := ioutil.WriteFile(f.Name(), []byte(s), 0644); nil != err {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Unix(0, 0)
+	g := NewFileGauge(f.Name(), parseInt64, time.Second).(*FileGauge)
+	g.now = func() time.Time { return now }
+
+	write("47\n")
+	if v := g.Value(); 47 != v {
+		t.Fatalf("g.Value(): 47 != %v\n", v)
+	}
+
+	write("48\n")
+	if v := g.Value(); 47 != v {
+		t.Fatalf("g.Value() within ttl: 47 != %v\n", v)
+	}
+	now = now.Add(time.Second)
+	if v := g.Value(); 48 != v {
+		t.Fatalf("g.Value(): 48 != %v\n", v)
+	}
+
+	write("garbage\n")
+	now = now.Add(time.Second)
+	if v := g.Value(); 48 != v {
+		t.Fatalf("g.Value() after parse error: 48 != %v\n", v)
+	}
+	os.Remove(f.Name())
+	now = now.Add(time.Second)
+	if v := g.Value(); 48 != v {
+		t.Fatalf("g.Value() after read error: 48 != %v\n", v)
+	}
+	if errors := g.Errors(); 2 != errors {
+		t.Fatalf("g.Errors(): 2 != %v\n", errors)
+	}
+}
@@ -89,3 +89,28 @@ func (g *StandardGaugeFloat64) Value() float64 {
 	defer g.mutex.Unlock()
 	return g.value
 }
+
+// SumGauges returns the sum of the values of the given gauges, for example
+// to total memory use across shards.
+func SumGauges(gs ...GaugeFloat64) float64 {
+	sum := 0.0
+	for _, g := range gs {
+		sum += g.Value()
+	}
+	return sum
+}
+
+// MaxGauges returns the largest value of the given gauges or zero if none are
+// given.
+func MaxGauges(gs ...GaugeFloat64) float64 {
+	if 0 == len(gs) {
+		return 0.0
+	}
+	max := gs[0].Value()
+	for _, g := range gs[1:] {
+		if v := g.Value(); v > max {
+			max = v
+		}
+	}
+	return max
+}
@@ -28,6 +28,24 @@ func TestGaugeFloat64Snapshot(t *testing.T) {
 	}
 }
 
+func TestMaxGauges(t *testing.T) {
+	if v := MaxGauges(); 0.0 != v {
+		t.Errorf("MaxGauges(): 0.0 != %v\n", v)
+	}
+	if v := MaxGauges(GaugeFloat64Snapshot(-1.5), GaugeFloat64Snapshot(2.5), GaugeFloat64Snapshot(1.0)); 2.5 != v {
+		t.Errorf("MaxGauges(): 2.5 != %v\n", v)
+	}
+}
+
+func TestSumGauges(t *testing.T) {
+	if v := SumGauges(); 0.0 != v {
+		t.Errorf("SumGauges(): 0.0 != %v\n", v)
+	}
+	if v := SumGauges(GaugeFloat64Snapshot(-1.5), GaugeFloat64Snapshot(2.5), GaugeFloat64Snapshot(1.0)); 2.0 != v {
+		t.Errorf("SumGauges(): 2.0 != %v\n", v)
+	}
+}
+
 func TestGetOrRegisterGaugeFloat64(t *testing.T) {
 	r := NewRegistry()
 	NewRegisteredGaugeFloat64("foo", r).Update(float64(47.0))
@@ -0,0 +1,48 @@
+package metrics
+
+import "time"
+
+// NewMultiGauge constructs a new MultiGauge which calls f every d duration
+// and launches a goroutine.  Call Stop to end the goroutine.
+func NewMultiGauge(r Registry, d time.Duration, f func() map[string]int64) *MultiGauge {
+	return NewMultiGaugeWithLifecycle(r, d, f, nil)
+}
+
+// NewMultiGaugeWithLifecycle constructs a new MultiGauge just like
+// NewMultiGauge but tracks its goroutine with l, if l is not nil.
+func NewMultiGaugeWithLifecycle(r Registry, d time.Duration, f func() map[string]int64, l *Lifecycle) *MultiGauge {
+	g := newMultiGauge(r, f)
+	startTicking(d, l, g.done, g.compute)
+	return g
+}
+
+// MultiGauge updates a family of Gauges from a single function returning
+// many related values at once, one Gauge per key.  Gauges for new keys are
+// registered as they appear; gauges whose keys disappear keep their last
+// value.
+type MultiGauge struct {
+	registry Registry
+	f        func() map[string]int64
+	done     chan struct{}
+}
+
+func newMultiGauge(r Registry, f func() map[string]int64) *MultiGauge {
+	if nil == r {
+		r = DefaultRegistry
+	}
+	g := &MultiGauge{registry: r, f: f, done: make(chan struct{})}
+	g.compute()
+	return g
+}
+
+// Stop ends the goroutine updating the gauges.  It must be called at most
+// once.
+func (g *MultiGauge) Stop() {
+	close(g.done)
+}
+
+func (g *MultiGauge) compute() {
+	for name, v := range g.f() {
+		GetOrRegisterGauge(name, g.registry).Update(v)
+	}
+}
@@ -0,0 +1,28 @@
+package metrics
+
+import "testing"
+
+func TestMultiGauge(t *testing.T) {
+	r := NewRegistry()
+	calls := int64(0)
+	g := newMultiGauge(r, func() map[string]int64 {
+		calls++
+		if 1 == calls {
+			return map[string]int64{"a": 1, "b": 2}
+		}
+		return map[string]int64{"a": calls * 10, "c": 3}
+	})
+	if v := r.Get("a").(Gauge).Value(); 1 != v {
+		t.Fatalf("a: 1 != %v\n", v)
+	}
+	runTicking(ticks(1), g.done, nil, g.compute)
+	if v := r.Get("a").(Gauge).Value(); 20 != v {
+		t.Errorf("a: 20 != %v\n", v)
+	}
+	if v := r.Get("b").(Gauge).Value(); 2 != v {
+		t.Errorf("b kept its last value: 2 != %v\n", v)
+	}
+	if v := r.Get("c").(Gauge).Value(); 3 != v {
+		t.Errorf("c: 3 != %v\n", v)
+	}
+}
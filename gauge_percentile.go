@@ -0,0 +1,39 @@
+package metrics
+
+import "time"
+
+// NewPercentileGauge constructs a new PercentileGauge which samples the given
+// percentile of h every d duration and launches a goroutine.  Call Stop to
+// end the goroutine.  When UseNilMetrics is set the gauge is never computed
+// and reads zero.
+func NewPercentileGauge(h Histogram, p float64, d time.Duration) *PercentileGauge {
+	return NewPercentileGaugeWithLifecycle(h, p, d, nil)
+}
+
+// NewPercentileGaugeWithLifecycle constructs a new PercentileGauge just like
+// NewPercentileGauge but tracks its goroutine with l, if l is not nil.
+func NewPercentileGaugeWithLifecycle(h Histogram, p float64, d time.Duration, l *Lifecycle) *PercentileGauge {
+	if UseNilMetrics {
+		return &PercentileGauge{&ComputedGauge{done: make(chan struct{})}}
+	}
+	g := newPercentileGauge(h, p)
+	startTicking(d, l, g.done, g.compute)
+	return g
+}
+
+// PercentileGauge is a Gauge whose value is a percentile of a Histogram,
+// recomputed on an interval rather than on every read.
+type PercentileGauge struct {
+	*ComputedGauge
+}
+
+func newPercentileGauge(h Histogram, p float64) *PercentileGauge {
+	return &PercentileGauge{newComputedGauge(func() int64 {
+		return int64(h.Percentile(p))
+	})}
+}
+
+// Update panics.
+func (*PercentileGauge) Update(int64) {
+	panic("Update called on a PercentileGauge")
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import "time"
+
+// NewSmoothedPercentileGauge constructs a new SmoothedPercentileGauge which
+// samples the given percentile of h every d duration, smoothing it with
+// weight alpha, and launches a goroutine.  Call Stop to end the goroutine.
+// When UseNilMetrics is set the gauge is never computed and reads zero.
+func NewSmoothedPercentileGauge(h Histogram, p float64, d time.Duration, alpha float64) *SmoothedPercentileGauge {
+	return NewSmoothedPercentileGaugeWithLifecycle(h, p, d, alpha, nil)
+}
+
+// NewSmoothedPercentileGaugeWithLifecycle constructs a new
+// SmoothedPercentileGauge just like NewSmoothedPercentileGauge but tracks its
+// goroutine with l, if l is not nil.
+func NewSmoothedPercentileGaugeWithLifecycle(h Histogram, p float64, d time.Duration, alpha float64, l *Lifecycle) *SmoothedPercentileGauge {
+	if UseNilMetrics {
+		return &SmoothedPercentileGauge{&ComputedGauge{done: make(chan struct{})}}
+	}
+	g := newSmoothedPercentileGauge(h, p, alpha)
+	startTicking(d, l, g.done, g.compute)
+	return g
+}
+
+// SmoothedPercentileGauge is a Gauge whose value is an exponentially-weighted
+// moving average of a percentile of a Histogram sampled on an interval, which
+// damps the jumps a raw percentile shows under light traffic.  Each sample is
+// weighted by alpha, between 0 and 1, and the previous average by 1-alpha.
+type SmoothedPercentileGauge struct {
+	*ComputedGauge
+}
+
+func newSmoothedPercentileGauge(h Histogram, p float64, alpha float64) *SmoothedPercentileGauge {
+	var smoothed float64
+	sampled := false
+	return &SmoothedPercentileGauge{newComputedGauge(func() int64 {
+		if v := h.Percentile(p); sampled {
+			smoothed += alpha * (v - smoothed)
+		} else {
+			smoothed, sampled = v, true
+		}
+		return int64(smoothed)
+	})}
+}
+
+// Update panics.
+func (*SmoothedPercentileGauge) Update(int64) {
+	panic("Update called on a SmoothedPercentileGauge")
+}
@@ -0,0 +1,28 @@
+package metrics
+
+import "testing"
+
+func TestSmoothedPercentileGauge(t *testing.T) {
+	h := NewHistogram(NewUniformSample(100))
+	h.Update(100)
+	g := newSmoothedPercentileGauge(h, 0.5, 0.5)
+	if v := g.Value(); 100 != v {
+		t.Fatalf("g.Value(): 100 != %v\n", v)
+	}
+	h.Clear()
+	h.Update(900)
+	runTicking(ticks(1), g.done, nil, g.compute)
+	if v := g.Value(); 500 != v {
+		t.Fatalf("g.Value() after spike: 500 != %v\n", v)
+	}
+	h.Clear()
+	h.Update(100)
+	runTicking(ticks(1), g.done, nil, g.compute)
+	if v := g.Value(); 300 != v {
+		t.Fatalf("g.Value() after spike subsides: 300 != %v\n", v)
+	}
+	runTicking(ticks(1), g.done, nil, g.compute)
+	if v := g.Value(); 200 != v {
+		t.Fatalf("g.Value(): 200 != %v\n", v)
+	}
+}
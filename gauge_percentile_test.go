@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPercentileGauge(t *testing.T) {
+	h := NewHistogram(NewUniformSample(100))
+	g := newPercentileGauge(h, 0.5)
+	if v := g.Value(); 0 != v {
+		t.Fatalf("g.Value(): 0 != %v\n", v)
+	}
+	h.Update(10)
+	if v := g.Value(); 0 != v {
+		t.Fatalf("g.Value() before tick: 0 != %v\n", v)
+	}
+	runTicking(ticks(1), g.done, nil, g.compute)
+	if v := g.Value(); 10 != v {
+		t.Fatalf("g.Value(): 10 != %v\n", v)
+	}
+	h.Update(20)
+	h.Update(30)
+	runTicking(ticks(1), g.done, nil, g.compute)
+	if v := g.Value(); 20 != v {
+		t.Fatalf("g.Value(): 20 != %v\n", v)
+	}
+}
+
+func TestPercentileGaugeNil(t *testing.T) {
+	UseNilMetrics = true
+	defer func() { UseNilMetrics = false }()
+	h := NewHistogram(NewUniformSample(100))
+	var g Gauge = NewPercentileGauge(h, 0.5, time.Hour)
+	if v := g.Value(); 0 != v {
+		t.Errorf("g.Value(): 0 != %v\n", v)
+	}
+	g.(*PercentileGauge).Stop()
+	NewSmoothedPercentileGauge(h, 0.5, time.Hour, 0.5).Stop()
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import "sync/atomic"
+
+// StructGauge holds a pointer to a composite value which is replaced
+// atomically, so readers always see one consistent value without locking.
+// Values must not be modified after they are stored.  A StructGauge is not
+// itself a registrable metric; register the Gauges returned by Field instead.
+type StructGauge[T any] struct {
+	value atomic.Pointer[T]
+}
+
+// NewStructGauge constructs a new StructGauge holding nil.
+func NewStructGauge[T any]() *StructGauge[T] {
+	return &StructGauge[T]{}
+}
+
+// Field returns a read-only Gauge whose value is the one f extracts from the
+// most recently stored value, or 0 if none has been stored.
+func (g *StructGauge[T]) Field(f func(*T) int64) Gauge {
+	return &structGaugeField[T]{gauge: g, f: f}
+}
+
+// Load returns the most recently stored value.
+func (g *StructGauge[T]) Load() *T {
+	return g.value.Load()
+}
+
+// Store replaces the gauge's value.
+func (g *StructGauge[T]) Store(v *T) {
+	g.value.Store(v)
+}
+
+// structGaugeField is the Gauge returned by StructGauge.Field.
+type structGaugeField[T any] struct {
+	gauge *StructGauge[T]
+	f     func(*T) int64
+}
+
+// Snapshot returns a read-only copy of the gauge.
+func (g *structGaugeField[T]) Snapshot() Gauge { return GaugeSnapshot(g.Value()) }
+
+// Update panics.
+func (*structGaugeField[T]) Update(int64) {
+	panic("Update called on a StructGauge field")
+}
+
+// Value returns the field of the most recently stored value.
+func (g *structGaugeField[T]) Value() int64 {
+	v := g.gauge.Load()
+	if nil == v {
+		return 0
+	}
+	return g.f(v)
+}
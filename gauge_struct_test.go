@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"sync"
+	"testing"
+)
+
+type structGaugeTestValue struct {
+	Used, Free, Total int64
+}
+
+func TestStructGauge(t *testing.T) {
+	g := NewStructGauge[structGaugeTestValue]()
+	if v := g.Load(); nil != v {
+		t.Fatal(v)
+	}
+	g.Store(&structGaugeTestValue{1, 2, 3})
+	if v := g.Load(); 1 != v.Used || 2 != v.Free || 3 != v.Total {
+		t.Fatal(v)
+	}
+}
+
+func TestStructGaugeField(t *testing.T) {
+	r := NewRegistry()
+	g := NewStructGauge[structGaugeTestValue]()
+	used := g.Field(func(v *structGaugeTestValue) int64 { return v.Used })
+	if err := r.Register("used", used); nil != err {
+		t.Fatal(err)
+	}
+	if v := used.Value(); 0 != v {
+		t.Errorf("used.Value(): 0 != %v\n", v)
+	}
+	g.Store(&structGaugeTestValue{1, 2, 3})
+	if v := r.Get("used").(Gauge).Value(); 1 != v {
+		t.Errorf("used: 1 != %v\n", v)
+	}
+}
+
+func TestStructGaugeConcurrent(t *testing.T) {
+	g := NewStructGauge[structGaugeTestValue]()
+	g.Store(&structGaugeTestValue{0, 100, 100})
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := int64(0); i <= 100; i++ {
+			g.Store(&structGaugeTestValue{i, 100 - i, 100})
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		if v := g.Load(); v.Used+v.Free != v.Total {
+			t.Fatal(v)
+		}
+	}
+	wg.Wait()
+	if v := g.Load(); 100 != v.Used {
+		t.Fatal(v)
+	}
+}
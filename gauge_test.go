@@ -28,6 +28,14 @@ func TestGaugeSnapshot(t *testing.T) {
 	}
 }
 
+func TestGaugeZeroValue(t *testing.T) {
+	var g StandardGauge
+	g.Update(47)
+	if v := g.Value(); 47 != v {
+		t.Errorf("g.Value(): 47 != %v\n", v)
+	}
+}
+
 func TestGetOrRegisterGauge(t *testing.T) {
 	r := NewRegistry()
 	NewRegisteredGauge("foo", r).Update(47)
@@ -1,21 +1,90 @@
 package metrics
 
 import (
-	"bufio"
+	"bytes"
 	"fmt"
+	"io"
 	"log"
 	"net"
+	"sort"
+	"sync"
 	"time"
 )
 
 // GraphiteConfig provides a container with configuration parameters for
 // the Graphite exporter
 type GraphiteConfig struct {
-	Addr          *net.TCPAddr  // Network address to connect to
-	Registry      Registry      // Registry to be exported
-	FlushInterval time.Duration // Flush interval
-	DurationUnit  time.Duration // Time conversion unit for durations
-	Prefix        string        // Prefix to be prepended to metric names
+	Addr                *net.TCPAddr        // Network address to connect to
+	Registry            Registry            // Registry to be exported
+	FlushInterval       time.Duration       // Flush interval
+	DurationUnit        time.Duration       // Time conversion unit for durations
+	Prefix              string              // Prefix to be prepended to metric names
+	SkipUnchanged       bool                // Omit gauges whose value hasn't changed since the last flush
+	MaxBatchBytes       int                 // If positive, write lines in batches of at most this many bytes
+	Workers             int                 // If greater than one, format metrics on this many goroutines and write them in name order
+	PercentileFormatter PercentileFormatter // Percentile labels; defaults to GraphitePercentileFormatter
+	EmitCounterRates    bool                // Also emit each counter's per-second rate since the last flush
+}
+
+// graphiteState carries what GraphiteWithConfig remembers between flushes.
+// Values formatted during a flush are staged and only become the basis for
+// the next flush once commit is called after they were written successfully.
+type graphiteState struct {
+	mutex          sync.Mutex
+	gauges         map[string]interface{} // last value sent for each gauge
+	counters       map[string][2]int64    // last count and time sent for each counter
+	stagedGauges   map[string]interface{}
+	stagedCounters map[string][2]int64
+}
+
+func newGraphiteState() *graphiteState {
+	return &graphiteState{
+		gauges:         make(map[string]interface{}),
+		counters:       make(map[string][2]int64),
+		stagedGauges:   make(map[string]interface{}),
+		stagedCounters: make(map[string][2]int64),
+	}
+}
+
+// changed stages v as the latest value of the named gauge and reports
+// whether it differs from the value sent in the previous flush.
+func (s *graphiteState) changed(name string, v interface{}) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	last, ok := s.gauges[name]
+	s.stagedGauges[name] = v
+	return !ok || last != v
+}
+
+// commit makes the values staged during this flush the basis for the next,
+// forgetting metrics which were not staged, such as unregistered ones.
+func (s *graphiteState) commit() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.gauges, s.counters = s.stagedGauges, s.stagedCounters
+	s.stagedGauges = make(map[string]interface{})
+	s.stagedCounters = make(map[string][2]int64)
+}
+
+// discard forgets the values staged during a flush that failed.
+func (s *graphiteState) discard() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.stagedGauges = make(map[string]interface{})
+	s.stagedCounters = make(map[string][2]int64)
+}
+
+// rate stages count as the latest count of the named counter at now and
+// returns its per-second rate since the previous flush, if there was one.
+func (s *graphiteState) rate(name string, count, now int64) (float64, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	last, ok := s.counters[name]
+	s.stagedCounters[name] = [2]int64{count, now}
+	if !ok || now <= last[1] {
+		return 0, false
+	}
+	return float64(count-last[0]) / float64(now-last[1]), true
 }
 
 // Graphite is a blocking exporter function which reports metrics in r
@@ -34,69 +103,190 @@ func Graphite(r Registry, d time.Duration, prefix string, addr *net.TCPAddr) {
 // GraphiteWithConfig is a blocking exporter function just like Graphite,
 // but it takes a GraphiteConfig instead.
 func GraphiteWithConfig(c GraphiteConfig) {
+	s := newGraphiteState()
 	for _ = range time.Tick(c.FlushInterval) {
-		if err := graphite(&c); nil != err {
+		if err := graphite(&c, s); nil != err {
 			log.Println(err)
 		}
 	}
 }
 
-func graphite(c *GraphiteConfig) error {
-	now := time.Now().Unix()
-	du := float64(c.DurationUnit)
+// GraphiteOnce writes every metric in c.Registry to w once, in the format
+// GraphiteWithConfig sends, and returns the first error encountered writing
+// them.  c.Addr and c.FlushInterval are ignored, and since nothing is
+// remembered between calls SkipUnchanged and EmitCounterRates have no
+// effect.
+func GraphiteOnce(c GraphiteConfig, w io.Writer) error {
+	return writeGraphite(w, &c, newGraphiteState(), time.Now().Unix())
+}
+
+func graphite(c *GraphiteConfig, s *graphiteState) error {
 	conn, err := net.DialTCP("tcp", nil, c.Addr)
 	if nil != err {
 		return err
 	}
 	defer conn.Close()
-	w := bufio.NewWriter(conn)
-	c.Registry.Each(func(name string, i interface{}) {
-		switch metric := i.(type) {
-		case Counter:
-			fmt.Fprintf(w, "%s.%s.count %d %d\n", c.Prefix, name, metric.Count(), now)
-		case Gauge:
-			fmt.Fprintf(w, "%s.%s.value %d %d\n", c.Prefix, name, metric.Value(), now)
-		case GaugeFloat64:
-			fmt.Fprintf(w, "%s.%s.value %f %d\n", c.Prefix, name, metric.Value(), now)
-		case Histogram:
-			h := metric.Snapshot()
-			ps := h.Percentiles([]float64{0.5, 0.75, 0.95, 0.99, 0.999})
-			fmt.Fprintf(w, "%s.%s.count %d %d\n", c.Prefix, name, h.Count(), now)
-			fmt.Fprintf(w, "%s.%s.min %d %d\n", c.Prefix, name, h.Min(), now)
-			fmt.Fprintf(w, "%s.%s.max %d %d\n", c.Prefix, name, h.Max(), now)
-			fmt.Fprintf(w, "%s.%s.mean %.2f %d\n", c.Prefix, name, h.Mean(), now)
-			fmt.Fprintf(w, "%s.%s.std-dev %.2f %d\n", c.Prefix, name, h.StdDev(), now)
-			fmt.Fprintf(w, "%s.%s.50-percentile %.2f %d\n", c.Prefix, name, ps[0], now)
-			fmt.Fprintf(w, "%s.%s.75-percentile %.2f %d\n", c.Prefix, name, ps[1], now)
-			fmt.Fprintf(w, "%s.%s.95-percentile %.2f %d\n", c.Prefix, name, ps[2], now)
-			fmt.Fprintf(w, "%s.%s.99-percentile %.2f %d\n", c.Prefix, name, ps[3], now)
-			fmt.Fprintf(w, "%s.%s.999-percentile %.2f %d\n", c.Prefix, name, ps[4], now)
-		case Meter:
-			m := metric.Snapshot()
-			fmt.Fprintf(w, "%s.%s.count %d %d\n", c.Prefix, name, m.Count(), now)
-			fmt.Fprintf(w, "%s.%s.one-minute %.2f %d\n", c.Prefix, name, m.Rate1(), now)
-			fmt.Fprintf(w, "%s.%s.five-minute %.2f %d\n", c.Prefix, name, m.Rate5(), now)
-			fmt.Fprintf(w, "%s.%s.fifteen-minute %.2f %d\n", c.Prefix, name, m.Rate15(), now)
-			fmt.Fprintf(w, "%s.%s.mean %.2f %d\n", c.Prefix, name, m.RateMean(), now)
-		case Timer:
-			t := metric.Snapshot()
-			ps := t.Percentiles([]float64{0.5, 0.75, 0.95, 0.99, 0.999})
-			fmt.Fprintf(w, "%s.%s.count %d %d\n", c.Prefix, name, t.Count(), now)
-			fmt.Fprintf(w, "%s.%s.min %d %d\n", c.Prefix, name, int64(du)*t.Min(), now)
-			fmt.Fprintf(w, "%s.%s.max %d %d\n", c.Prefix, name, int64(du)*t.Max(), now)
-			fmt.Fprintf(w, "%s.%s.mean %.2f %d\n", c.Prefix, name, du*t.Mean(), now)
-			fmt.Fprintf(w, "%s.%s.std-dev %.2f %d\n", c.Prefix, name, du*t.StdDev(), now)
-			fmt.Fprintf(w, "%s.%s.50-percentile %.2f %d\n", c.Prefix, name, du*ps[0], now)
-			fmt.Fprintf(w, "%s.%s.75-percentile %.2f %d\n", c.Prefix, name, du*ps[1], now)
-			fmt.Fprintf(w, "%s.%s.95-percentile %.2f %d\n", c.Prefix, name, du*ps[2], now)
-			fmt.Fprintf(w, "%s.%s.99-percentile %.2f %d\n", c.Prefix, name, du*ps[3], now)
-			fmt.Fprintf(w, "%s.%s.999-percentile %.2f %d\n", c.Prefix, name, du*ps[4], now)
-			fmt.Fprintf(w, "%s.%s.one-minute %.2f %d\n", c.Prefix, name, t.Rate1(), now)
-			fmt.Fprintf(w, "%s.%s.five-minute %.2f %d\n", c.Prefix, name, t.Rate5(), now)
-			fmt.Fprintf(w, "%s.%s.fifteen-minute %.2f %d\n", c.Prefix, name, t.Rate15(), now)
-			fmt.Fprintf(w, "%s.%s.mean-rate %.2f %d\n", c.Prefix, name, t.RateMean(), now)
+	return writeGraphite(conn, c, s, time.Now().Unix())
+}
+
+// writeGraphite writes every metric in the registry to out and returns the
+// first error encountered writing them.  s is only updated if every write
+// succeeds.
+func writeGraphite(out io.Writer, c *GraphiteConfig, s *graphiteState, now int64) error {
+	w := &graphiteBatch{out: out, max: c.MaxBatchBytes}
+	if 1 < c.Workers {
+		for _, b := range formatGraphite(c, s, now) {
+			w.Write(b)
+			if 0 >= c.MaxBatchBytes {
+				w.Flush()
+			}
 		}
-		w.Flush()
-	})
+	} else {
+		c.Registry.Each(func(name string, i interface{}) {
+			writeGraphiteMetric(w, c, s, name, i, now)
+			if 0 >= c.MaxBatchBytes {
+				w.Flush()
+			}
+		})
+	}
+	if err := w.Flush(); nil != err {
+		s.discard()
+		return err
+	}
+	s.commit()
 	return nil
 }
+
+// formatGraphite snapshots and formats every metric in the registry using a
+// pool of c.Workers goroutines and returns each metric's lines, sorted by
+// metric name.
+func formatGraphite(c *GraphiteConfig, s *graphiteState, now int64) [][]byte {
+	metrics := make(map[string]interface{})
+	c.Registry.Each(func(name string, i interface{}) {
+		metrics[name] = i
+	})
+	names := make([]string, 0, len(metrics))
+	for name, _ := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	formatted := make([][]byte, len(names))
+	work := make(chan int)
+	wg := &sync.WaitGroup{}
+	wg.Add(c.Workers)
+	for i := 0; i < c.Workers; i++ {
+		go func() {
+			defer wg.Done()
+			for j := range work {
+				b := &bytes.Buffer{}
+				writeGraphiteMetric(b, c, s, names[j], metrics[names[j]], now)
+				formatted[j] = b.Bytes()
+			}
+		}()
+	}
+	for j := range names {
+		work <- j
+	}
+	close(work)
+	wg.Wait()
+	return formatted
+}
+
+func writeGraphiteMetric(w io.Writer, c *GraphiteConfig, s *graphiteState, name string, i interface{}, now int64) {
+	du := float64(c.DurationUnit)
+	pf := c.PercentileFormatter
+	if nil == pf {
+		pf = GraphitePercentileFormatter
+	}
+	percentiles := []float64{0.5, 0.75, 0.95, 0.99, 0.999}
+	switch metric := i.(type) {
+	case Counter:
+		count := metric.Count()
+		fmt.Fprintf(w, "%s.%s.count %d %d\n", c.Prefix, name, count, now)
+		if c.EmitCounterRates {
+			if rate, ok := s.rate(name, count, now); ok {
+				fmt.Fprintf(w, "%s.%s.rate %.2f %d\n", c.Prefix, name, rate, now)
+			}
+		}
+	case Gauge:
+		v := metric.Value()
+		if s.changed(name, v) || !c.SkipUnchanged {
+			fmt.Fprintf(w, "%s.%s.value %d %d\n", c.Prefix, name, v, now)
+		}
+	case GaugeFloat64:
+		v := metric.Value()
+		if s.changed(name, v) || !c.SkipUnchanged {
+			fmt.Fprintf(w, "%s.%s.value %f %d\n", c.Prefix, name, v, now)
+		}
+	case Histogram:
+		h := metric.Snapshot()
+		ps := h.Percentiles(percentiles)
+		fmt.Fprintf(w, "%s.%s.count %d %d\n", c.Prefix, name, h.Count(), now)
+		fmt.Fprintf(w, "%s.%s.min %d %d\n", c.Prefix, name, h.Min(), now)
+		fmt.Fprintf(w, "%s.%s.max %d %d\n", c.Prefix, name, h.Max(), now)
+		fmt.Fprintf(w, "%s.%s.mean %.2f %d\n", c.Prefix, name, h.Mean(), now)
+		fmt.Fprintf(w, "%s.%s.std-dev %.2f %d\n", c.Prefix, name, h.StdDev(), now)
+		for j, p := range percentiles {
+			fmt.Fprintf(w, "%s.%s.%s %.2f %d\n", c.Prefix, name, pf(p), ps[j], now)
+		}
+	case Meter:
+		m := metric.Snapshot()
+		fmt.Fprintf(w, "%s.%s.count %d %d\n", c.Prefix, name, m.Count(), now)
+		fmt.Fprintf(w, "%s.%s.one-minute %.2f %d\n", c.Prefix, name, m.Rate1(), now)
+		fmt.Fprintf(w, "%s.%s.five-minute %.2f %d\n", c.Prefix, name, m.Rate5(), now)
+		fmt.Fprintf(w, "%s.%s.fifteen-minute %.2f %d\n", c.Prefix, name, m.Rate15(), now)
+		fmt.Fprintf(w, "%s.%s.mean %.2f %d\n", c.Prefix, name, m.RateMean(), now)
+	case Timer:
+		t := metric.Snapshot()
+		ps := t.Percentiles(percentiles)
+		fmt.Fprintf(w, "%s.%s.count %d %d\n", c.Prefix, name, t.Count(), now)
+		fmt.Fprintf(w, "%s.%s.min %d %d\n", c.Prefix, name, int64(du)*t.Min(), now)
+		fmt.Fprintf(w, "%s.%s.max %d %d\n", c.Prefix, name, int64(du)*t.Max(), now)
+		fmt.Fprintf(w, "%s.%s.mean %.2f %d\n", c.Prefix, name, du*t.Mean(), now)
+		fmt.Fprintf(w, "%s.%s.std-dev %.2f %d\n", c.Prefix, name, du*t.StdDev(), now)
+		for j, p := range percentiles {
+			fmt.Fprintf(w, "%s.%s.%s %.2f %d\n", c.Prefix, name, pf(p), du*ps[j], now)
+		}
+		fmt.Fprintf(w, "%s.%s.one-minute %.2f %d\n", c.Prefix, name, t.Rate1(), now)
+		fmt.Fprintf(w, "%s.%s.five-minute %.2f %d\n", c.Prefix, name, t.Rate5(), now)
+		fmt.Fprintf(w, "%s.%s.fifteen-minute %.2f %d\n", c.Prefix, name, t.Rate15(), now)
+		fmt.Fprintf(w, "%s.%s.mean-rate %.2f %d\n", c.Prefix, name, t.RateMean(), now)
+	}
+}
+
+// graphiteBatch buffers lines and writes them out in batches of at most max
+// bytes, never splitting a line; a line longer than max is written alone.
+// Each call to Write must contain only whole lines.  Once a write to out
+// fails, every later Write and Flush returns that error without writing.
+type graphiteBatch struct {
+	out io.Writer
+	max int
+	buf []byte
+	err error
+}
+
+func (b *graphiteBatch) Flush() error {
+	if nil != b.err || 0 == len(b.buf) {
+		return b.err
+	}
+	_, b.err = b.out.Write(b.buf)
+	b.buf = b.buf[:0]
+	return b.err
+}
+
+func (b *graphiteBatch) Write(p []byte) (int, error) {
+	if nil != b.err {
+		return 0, b.err
+	}
+	n := 0
+	for _, line := range bytes.SplitAfter(p, []byte("\n")) {
+		if 0 < b.max && 0 < len(b.buf) && len(b.buf)+len(line) > b.max {
+			if err := b.Flush(); nil != err {
+				return n, err
+			}
+		}
+		b.buf = append(b.buf, line...)
+		n += len(line)
+	}
+	return n, nil
+}
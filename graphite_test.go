@@ -1,10 +1,35 @@
 package metrics
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"net"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
 	"time"
 )
 
+func BenchmarkGraphite(b *testing.B) {
+	benchmarkGraphite(b, 0)
+}
+
+func BenchmarkGraphiteWorkers(b *testing.B) {
+	benchmarkGraphite(b, 8)
+}
+
+func benchmarkGraphite(b *testing.B, workers int) {
+	config := &GraphiteConfig{Registry: largeRegistry(), Prefix: "p", Workers: workers}
+	s := newGraphiteState()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		writeGraphite(ioutil.Discard, config, s, 1)
+	}
+}
+
 func ExampleGraphite() {
 	addr, _ := net.ResolveTCPAddr("net", ":2003")
 	go Graphite(DefaultRegistry, 1*time.Second, "some.prefix", addr)
@@ -19,3 +44,270 @@ func ExampleGraphiteWithConfig() {
 		DurationUnit:  time.Millisecond,
 	})
 }
+
+func TestGraphiteSkipUnchanged(t *testing.T) {
+	r := NewRegistry()
+	c := NewRegisteredCounter("counter", r)
+	g := NewRegisteredGauge("gauge", r)
+	gf := NewRegisteredGaugeFloat64("gaugefloat64", r)
+	config := &GraphiteConfig{Registry: r, Prefix: "p", SkipUnchanged: true}
+	s := newGraphiteState()
+	c.Inc(1)
+	g.Update(1)
+	gf.Update(1.5)
+
+	b := &bytes.Buffer{}
+	writeGraphite(b, config, s, 1)
+	if n := bytes.Count(b.Bytes(), []byte("\n")); 3 != n {
+		t.Fatalf("first flush: 3 != %v lines\n%s", n, b)
+	}
+
+	b.Reset()
+	writeGraphite(b, config, s, 2)
+	if lines := sortedLines(b); !reflect.DeepEqual([]string{"p.counter.count 1 2"}, lines) {
+		t.Fatal(lines)
+	}
+
+	b.Reset()
+	g.Update(2)
+	writeGraphite(b, config, s, 3)
+	if lines := sortedLines(b); !reflect.DeepEqual([]string{"p.counter.count 1 3", "p.gauge.value 2 3"}, lines) {
+		t.Fatal(lines)
+	}
+
+	b.Reset()
+	config.SkipUnchanged = false
+	writeGraphite(b, config, s, 4)
+	if n := bytes.Count(b.Bytes(), []byte("\n")); 3 != n {
+		t.Fatalf("SkipUnchanged off: 3 != %v lines\n%s", n, b)
+	}
+}
+
+func TestGraphitePercentileFormatter(t *testing.T) {
+	r := NewRegistry()
+	NewRegisteredHistogram("h", r, NewUniformSample(100)).Update(1)
+	b := &bytes.Buffer{}
+	writeGraphite(b, &GraphiteConfig{Registry: r, Prefix: "p"}, newGraphiteState(), 1)
+	if !bytes.Contains(b.Bytes(), []byte("p.h.999-percentile 1.00 1\n")) {
+		t.Errorf("default formatter:\n%s", b)
+	}
+	b.Reset()
+	writeGraphite(b, &GraphiteConfig{Registry: r, Prefix: "p", PercentileFormatter: StatsDPercentileFormatter}, newGraphiteState(), 1)
+	if !bytes.Contains(b.Bytes(), []byte("p.h.p99_9 1.00 1\n")) {
+		t.Errorf("StatsD formatter:\n%s", b)
+	}
+	if bytes.Contains(b.Bytes(), []byte("-percentile")) {
+		t.Errorf("StatsD formatter:\n%s", b)
+	}
+}
+
+func TestGraphiteEmitCounterRates(t *testing.T) {
+	r := NewRegistry()
+	c := NewRegisteredCounter("counter", r)
+	config := &GraphiteConfig{Registry: r, Prefix: "p", EmitCounterRates: true}
+	s := newGraphiteState()
+	c.Inc(10)
+
+	b := &bytes.Buffer{}
+	writeGraphite(b, config, s, 100)
+	if lines := sortedLines(b); !reflect.DeepEqual([]string{"p.counter.count 10 100"}, lines) {
+		t.Fatal(lines)
+	}
+
+	b.Reset()
+	c.Inc(50)
+	writeGraphite(b, config, s, 110)
+	if lines := sortedLines(b); !reflect.DeepEqual([]string{"p.counter.count 60 110", "p.counter.rate 5.00 110"}, lines) {
+		t.Fatal(lines)
+	}
+
+	b.Reset()
+	writeGraphite(b, config, s, 120)
+	if lines := sortedLines(b); !reflect.DeepEqual([]string{"p.counter.count 60 120", "p.counter.rate 0.00 120"}, lines) {
+		t.Fatal(lines)
+	}
+}
+
+func TestGraphiteMaxBatchBytes(t *testing.T) {
+	r := NewRegistry()
+	NewRegisteredCounter("counter", r).Inc(1)
+	NewRegisteredGauge("gauge", r).Update(2)
+	NewRegisteredMeter("meter", r).Mark(3)
+	NewRegisteredTimer("timer", r).Update(4)
+	config := &GraphiteConfig{Registry: r, Prefix: "p", MaxBatchBytes: 100}
+	w := &recordingWriter{}
+	writeGraphite(w, config, newGraphiteState(), 1)
+	all := &bytes.Buffer{}
+	for i, write := range w.writes {
+		if 100 < len(write) {
+			t.Errorf("write %d: %d bytes > 100\n%s", i, len(write), write)
+		}
+		if '\n' != write[len(write)-1] {
+			t.Errorf("write %d split a line:\n%s", i, write)
+		}
+		all.Write(write)
+	}
+	if n := bytes.Count(all.Bytes(), []byte("\n")); 1+1+5+14 != n {
+		t.Fatalf("%d lines\n%s", n, all)
+	}
+	if n := len(w.writes); all.Len()/100 >= n {
+		t.Fatalf("%d writes for %d bytes", n, all.Len())
+	}
+
+	// A line longer than the limit is written alone rather than split.
+	config.MaxBatchBytes = 10
+	w = &recordingWriter{}
+	writeGraphite(w, config, newGraphiteState(), 1)
+	if n := len(w.writes); 21 != n {
+		t.Fatalf("21 != %d writes", n)
+	}
+}
+
+func TestGraphiteWriteError(t *testing.T) {
+	r := NewRegistry()
+	NewRegisteredCounter("foo", r)
+	NewRegisteredCounter("bar", r)
+	for _, config := range []*GraphiteConfig{
+		{Registry: r, Prefix: "p"},
+		{Registry: r, Prefix: "p", MaxBatchBytes: 1000},
+		{Registry: r, Prefix: "p", Workers: 2},
+	} {
+		w := &failingWriter{}
+		if err := writeGraphite(w, config, newGraphiteState(), 1); errWriteFailed != err {
+			t.Errorf("%+v: %v\n", config, err)
+		}
+		if 1 != w.writes {
+			t.Errorf("%+v: 1 != %v writes\n", config, w.writes)
+		}
+	}
+}
+
+func TestGraphiteStateForgetsUnregistered(t *testing.T) {
+	r := NewRegistry()
+	NewRegisteredGauge("gauge", r).Update(1)
+	config := &GraphiteConfig{Registry: r, Prefix: "p", SkipUnchanged: true}
+	s := newGraphiteState()
+	writeGraphite(&bytes.Buffer{}, config, s, 1)
+	r.Unregister("gauge")
+	writeGraphite(&bytes.Buffer{}, config, s, 2)
+	if 0 != len(s.gauges) {
+		t.Fatal(s.gauges)
+	}
+
+	NewRegisteredGauge("gauge", r).Update(1)
+	b := &bytes.Buffer{}
+	writeGraphite(b, config, s, 3)
+	if lines := sortedLines(b); !reflect.DeepEqual([]string{"p.gauge.value 1 3"}, lines) {
+		t.Fatal(lines)
+	}
+}
+
+func TestGraphiteStateAfterWriteError(t *testing.T) {
+	r := NewRegistry()
+	c := NewRegisteredCounter("counter", r)
+	NewRegisteredGauge("gauge", r).Update(1)
+	config := &GraphiteConfig{Registry: r, Prefix: "p", SkipUnchanged: true, EmitCounterRates: true}
+	s := newGraphiteState()
+	c.Inc(10)
+	if err := writeGraphite(&bytes.Buffer{}, config, s, 100); nil != err {
+		t.Fatal(err)
+	}
+
+	c.Inc(10)
+	if err := writeGraphite(&failingWriter{}, config, s, 110); errWriteFailed != err {
+		t.Fatal(err)
+	}
+
+	b := &bytes.Buffer{}
+	c.Inc(20)
+	if err := writeGraphite(b, config, s, 120); nil != err {
+		t.Fatal(err)
+	}
+	if lines := sortedLines(b); !reflect.DeepEqual([]string{"p.counter.count 40 120", "p.counter.rate 1.50 120"}, lines) {
+		t.Fatal(lines)
+	}
+
+	s = newGraphiteState()
+	if err := writeGraphite(&failingWriter{}, config, s, 130); errWriteFailed != err {
+		t.Fatal(err)
+	}
+	b.Reset()
+	if err := writeGraphite(b, config, s, 140); nil != err {
+		t.Fatal(err)
+	}
+	if lines := sortedLines(b); !reflect.DeepEqual([]string{"p.counter.count 40 140", "p.gauge.value 1 140"}, lines) {
+		t.Fatal(lines)
+	}
+}
+
+func TestGraphiteWorkers(t *testing.T) {
+	r := largeRegistry()
+	serial := &bytes.Buffer{}
+	writeGraphite(serial, &GraphiteConfig{Registry: r, Prefix: "p"}, newGraphiteState(), 1)
+
+	config := &GraphiteConfig{Registry: r, Prefix: "p", Workers: 4}
+	concurrent := &bytes.Buffer{}
+	writeGraphite(concurrent, config, newGraphiteState(), 1)
+	if !reflect.DeepEqual(sortedLines(serial), sortedLines(concurrent)) {
+		t.Fatal("concurrent output differs from serial output")
+	}
+
+	// Metrics are written in name order and each metric's lines stay
+	// together and in order.
+	expected := &bytes.Buffer{}
+	for i := 0; i < 100; i++ {
+		name := fmt.Sprintf("histogram%03d", i)
+		writeGraphiteMetric(expected, config, newGraphiteState(), name, r.Get(name), 1)
+	}
+	for i := 0; i < 100; i++ {
+		name := fmt.Sprintf("timer%03d", i)
+		writeGraphiteMetric(expected, config, newGraphiteState(), name, r.Get(name), 1)
+	}
+	if expected.String() != concurrent.String() {
+		t.Fatalf("concurrent output out of order:\n%s", concurrent)
+	}
+}
+
+// largeRegistry returns a registry of 100 histograms and 100 timers.
+func largeRegistry() Registry {
+	r := NewRegistry()
+	for i := 0; i < 100; i++ {
+		h := NewRegisteredHistogram(fmt.Sprintf("histogram%03d", i), r, NewUniformSample(1028))
+		tm := NewRegisteredTimer(fmt.Sprintf("timer%03d", i), r)
+		for j := 0; j < 1028; j++ {
+			h.Update(int64(i * j))
+			tm.Update(time.Duration(i * j))
+		}
+	}
+	return r
+}
+
+// recordingWriter records each call to Write separately.
+type recordingWriter struct {
+	writes [][]byte
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, append([]byte(nil), p...))
+	return len(p), nil
+}
+
+var errWriteFailed = errors.New("write failed")
+
+// failingWriter fails every call to Write, counting them.
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return 0, errWriteFailed
+}
+
+// sortedLines returns the lines written to b in sorted order, since
+// registries are iterated in no particular order.
+func sortedLines(b *bytes.Buffer) []string {
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	sort.Strings(lines)
+	return lines
+}
@@ -1,5 +1,10 @@
 package metrics
 
+import (
+	"fmt"
+	"time"
+)
+
 // Healthchecks hold an error value describing an arbitrary up/down status.
 type Healthcheck interface {
 	Check()
@@ -59,3 +64,63 @@ func (h *StandardHealthcheck) Healthy() {
 func (h *StandardHealthcheck) Unhealthy(err error) {
 	h.err = err
 }
+
+// NewErrorRateHealthcheck constructs a new ErrorRateHealthcheck which trips
+// when the one-minute rate of errors exceeds maxRatio of the one-minute rate
+// of total and recovers once the ratio has stayed below maxRatio for
+// cooldown.
+func NewErrorRateHealthcheck(errors, total Meter, maxRatio float64, cooldown time.Duration) Healthcheck {
+	if UseNilMetrics {
+		return NilHealthcheck{}
+	}
+	return &ErrorRateHealthcheck{
+		errors:   errors,
+		total:    total,
+		maxRatio: maxRatio,
+		cooldown: cooldown,
+		now:      time.Now,
+	}
+}
+
+// ErrorRateHealthcheck is a circuit-breaker-style Healthcheck driven by the
+// ratio of two meters' rates.
+type ErrorRateHealthcheck struct {
+	errors, total Meter
+	maxRatio      float64
+	cooldown      time.Duration
+	err           error
+	trippedAt     time.Time
+	now           func() time.Time
+}
+
+// Check compares the current error ratio to the threshold, tripping the
+// healthcheck or, once the cooldown has elapsed, marking it healthy again.
+func (h *ErrorRateHealthcheck) Check() {
+	ratio := 0.0
+	if total := h.total.Rate1(); 0 < total {
+		ratio = h.errors.Rate1() / total
+	}
+	now := h.now()
+	if ratio > h.maxRatio {
+		h.trippedAt = now
+		h.err = fmt.Errorf("error ratio %.4f exceeds %.4f", ratio, h.maxRatio)
+	} else if nil != h.err && now.Sub(h.trippedAt) >= h.cooldown {
+		h.err = nil
+	}
+}
+
+// Error returns the healthcheck's status, which will be nil if it is healthy.
+func (h *ErrorRateHealthcheck) Error() error {
+	return h.err
+}
+
+// Healthy marks the healthcheck as healthy.
+func (h *ErrorRateHealthcheck) Healthy() {
+	h.err = nil
+}
+
+// Unhealthy marks the healthcheck as unhealthy and restarts the cooldown.
+func (h *ErrorRateHealthcheck) Unhealthy(err error) {
+	h.trippedAt = h.now()
+	h.err = err
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestErrorRateHealthcheck(t *testing.T) {
+	errors, total := &MeterSnapshot{}, &MeterSnapshot{}
+	now := time.Unix(0, 0)
+	h := NewErrorRateHealthcheck(errors, total, 0.1, time.Minute).(*ErrorRateHealthcheck)
+	h.now = func() time.Time { return now }
+
+	h.Check()
+	if err := h.Error(); nil != err {
+		t.Fatal(err)
+	}
+
+	errors.rate1, total.rate1 = 5, 10
+	h.Check()
+	if err := h.Error(); nil == err {
+		t.Fatal("h.Error(): nil after exceeding the ratio")
+	}
+
+	errors.rate1 = 0
+	now = now.Add(30 * time.Second)
+	h.Check()
+	if err := h.Error(); nil == err {
+		t.Fatal("h.Error(): nil before the cooldown elapsed")
+	}
+
+	now = now.Add(30 * time.Second)
+	h.Check()
+	if err := h.Error(); nil != err {
+		t.Fatal(err)
+	}
+}
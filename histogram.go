@@ -1,5 +1,10 @@
 package metrics
 
+import (
+	"fmt"
+	"sync"
+)
+
 // Histograms calculate distribution statistics from a series of int64 values.
 type Histogram interface {
 	Clear()
@@ -142,51 +147,91 @@ func (NilHistogram) Update(v int64) {}
 func (NilHistogram) Variance() float64 { return 0.0 }
 
 // StandardHistogram is the standard implementation of a Histogram and uses a
-// Sample to bound its memory use.
+// Sample to bound its memory use.  Its zero value is ready to use and
+// samples with the same exponentially-decaying reservoir as NewTimer.
 type StandardHistogram struct {
+	once   sync.Once
 	sample Sample
 }
 
 // Clear clears the histogram and its sample.
-func (h *StandardHistogram) Clear() { h.sample.Clear() }
+func (h *StandardHistogram) Clear() { h.s().Clear() }
 
 // Count returns the number of samples recorded since the histogram was last
 // cleared.
-func (h *StandardHistogram) Count() int64 { return h.sample.Count() }
+func (h *StandardHistogram) Count() int64 { return h.s().Count() }
 
 // Max returns the maximum value in the sample.
-func (h *StandardHistogram) Max() int64 { return h.sample.Max() }
+func (h *StandardHistogram) Max() int64 { return h.s().Max() }
 
 // Mean returns the mean of the values in the sample.
-func (h *StandardHistogram) Mean() float64 { return h.sample.Mean() }
+func (h *StandardHistogram) Mean() float64 { return h.s().Mean() }
 
 // Min returns the minimum value in the sample.
-func (h *StandardHistogram) Min() int64 { return h.sample.Min() }
+func (h *StandardHistogram) Min() int64 { return h.s().Min() }
 
 // Percentile returns an arbitrary percentile of the values in the sample.
 func (h *StandardHistogram) Percentile(p float64) float64 {
-	return h.sample.Percentile(p)
+	return h.s().Percentile(p)
 }
 
 // Percentiles returns a slice of arbitrary percentiles of the values in the
 // sample.
 func (h *StandardHistogram) Percentiles(ps []float64) []float64 {
-	return h.sample.Percentiles(ps)
+	return h.s().Percentiles(ps)
 }
 
 // Sample returns the Sample underlying the histogram.
-func (h *StandardHistogram) Sample() Sample { return h.sample }
+func (h *StandardHistogram) Sample() Sample { return h.s() }
 
 // Snapshot returns a read-only copy of the histogram.
 func (h *StandardHistogram) Snapshot() Histogram {
-	return &HistogramSnapshot{sample: h.sample.Snapshot().(*SampleSnapshot)}
+	return &HistogramSnapshot{sample: h.s().Snapshot().(*SampleSnapshot)}
 }
 
 // StdDev returns the standard deviation of the values in the sample.
-func (h *StandardHistogram) StdDev() float64 { return h.sample.StdDev() }
+func (h *StandardHistogram) StdDev() float64 { return h.s().StdDev() }
 
 // Update samples a new value.
-func (h *StandardHistogram) Update(v int64) { h.sample.Update(v) }
+func (h *StandardHistogram) Update(v int64) { h.s().Update(v) }
 
 // Variance returns the variance of the values in the sample.
-func (h *StandardHistogram) Variance() float64 { return h.sample.Variance() }
+func (h *StandardHistogram) Variance() float64 { return h.s().Variance() }
+
+// s returns the histogram's sample, first giving a zero-value histogram a
+// default one.
+func (h *StandardHistogram) s() Sample {
+	h.once.Do(func() {
+		if nil == h.sample {
+			h.sample = NewExpDecaySample(1028, 0.015)
+		}
+	})
+	return h.sample
+}
+
+// SafeUpdate updates h with v, recovering from any panic such as the one
+// raised by Update on a HistogramSnapshot.  It reports whether a panic was
+// recovered.
+func SafeUpdate(h Histogram, v int64) (recovered bool) {
+	defer func() {
+		if r := recover(); nil != r {
+			recovered = true
+		}
+	}()
+	h.Update(v)
+	return false
+}
+
+// AssertNoRegression returns an error describing the regression if the p
+// percentile of candidate exceeds that of baseline by more than tolerancePct
+// percent, or nil otherwise.  It is intended for tests guarding latency.
+func AssertNoRegression(baseline, candidate Histogram, p float64, tolerancePct float64) error {
+	b, c := baseline.Percentile(p), candidate.Percentile(p)
+	if limit := b * (1 + tolerancePct/100); c > limit {
+		return fmt.Errorf(
+			"%g percentile regressed: %.2f > %.2f (baseline %.2f + %g%%)",
+			p, c, limit, b, tolerancePct,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package metrics
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// AsyncHistograms are Histograms whose Update never blocks.
+type AsyncHistogram interface {
+	Histogram
+	Close()
+	Dropped() int64
+	Flush()
+}
+
+// NewAsyncHistogram constructs a new StandardAsyncHistogram wrapping inner
+// which buffers up to buffer updates and launches a goroutine to apply them.
+// Call Close to end the goroutine.
+func NewAsyncHistogram(inner Histogram, buffer int) AsyncHistogram {
+	if UseNilMetrics {
+		return NilAsyncHistogram{}
+	}
+	h := newAsyncHistogram(inner, buffer)
+	go h.run(nil)
+	return h
+}
+
+// NewAsyncHistogramWithLifecycle constructs a new StandardAsyncHistogram just
+// like NewAsyncHistogram but tracks its goroutine with l, if l is not nil.
+// Stopping l closes the histogram.
+func NewAsyncHistogramWithLifecycle(inner Histogram, buffer int, l *Lifecycle) AsyncHistogram {
+	if UseNilMetrics {
+		return NilAsyncHistogram{}
+	}
+	h := newAsyncHistogram(inner, buffer)
+	if nil == l {
+		go h.run(nil)
+	} else {
+		l.Go(h.run)
+	}
+	return h
+}
+
+// NilAsyncHistogram is a no-op AsyncHistogram.
+type NilAsyncHistogram struct {
+	NilHistogram
+}
+
+// Close is a no-op.
+func (NilAsyncHistogram) Close() {}
+
+// Dropped is a no-op.
+func (NilAsyncHistogram) Dropped() int64 { return 0 }
+
+// Flush is a no-op.
+func (NilAsyncHistogram) Flush() {}
+
+// StandardAsyncHistogram is the standard implementation of an AsyncHistogram:
+// values are queued on a buffered channel and applied to the wrapped
+// Histogram by a background goroutine, and values arriving while the buffer
+// is full or after the histogram is closed are dropped and counted.  Reads
+// are served by the wrapped Histogram and so are only eventually consistent
+// with updates; call Flush first to read your writes.
+type StandardAsyncHistogram struct {
+	dropped   atomic.Int64
+	histogram Histogram
+	updates   chan int64
+	flushes   chan chan struct{}
+	closing   chan struct{} // closed by Close to ask the goroutine to stop
+	closed    chan struct{} // closed by the goroutine once it has stopped
+	once      sync.Once
+	mutex     sync.RWMutex // held for reading while queueing an update
+	stopped   bool         // set, holding mutex, once no update may be queued
+}
+
+func newAsyncHistogram(inner Histogram, buffer int) *StandardAsyncHistogram {
+	return &StandardAsyncHistogram{
+		histogram: inner,
+		updates:   make(chan int64, buffer),
+		flushes:   make(chan chan struct{}),
+		closing:   make(chan struct{}),
+		closed:    make(chan struct{}),
+	}
+}
+
+// Clear clears the wrapped histogram.  Updates still queued are applied
+// afterwards.
+func (h *StandardAsyncHistogram) Clear() { h.histogram.Clear() }
+
+// Close applies every queued update and ends the goroutine.  Later updates
+// are dropped.  It is safe to call more than once and concurrently with
+// Update and Flush.
+func (h *StandardAsyncHistogram) Close() {
+	h.once.Do(func() { close(h.closing) })
+	<-h.closed
+}
+
+// Count returns the number of samples applied to the wrapped histogram.
+func (h *StandardAsyncHistogram) Count() int64 { return h.histogram.Count() }
+
+// Dropped returns the number of updates dropped because the buffer was full
+// or the histogram was closed.
+func (h *StandardAsyncHistogram) Dropped() int64 { return h.dropped.Load() }
+
+// Flush blocks until every update queued before the call has been applied.
+// It returns immediately once the histogram is closed, by which time every
+// queued update has been applied.
+func (h *StandardAsyncHistogram) Flush() {
+	c := make(chan struct{})
+	select {
+	case h.flushes <- c:
+		<-c
+	case <-h.closed:
+	}
+}
+
+// Max returns the maximum value in the sample.
+func (h *StandardAsyncHistogram) Max() int64 { return h.histogram.Max() }
+
+// Mean returns the mean of the values in the sample.
+func (h *StandardAsyncHistogram) Mean() float64 { return h.histogram.Mean() }
+
+// Min returns the minimum value in the sample.
+func (h *StandardAsyncHistogram) Min() int64 { return h.histogram.Min() }
+
+// Percentile returns an arbitrary percentile of the values in the sample.
+func (h *StandardAsyncHistogram) Percentile(p float64) float64 {
+	return h.histogram.Percentile(p)
+}
+
+// Percentiles returns a slice of arbitrary percentiles of the values in the
+// sample.
+func (h *StandardAsyncHistogram) Percentiles(ps []float64) []float64 {
+	return h.histogram.Percentiles(ps)
+}
+
+// Sample returns the Sample underlying the wrapped histogram.
+func (h *StandardAsyncHistogram) Sample() Sample { return h.histogram.Sample() }
+
+// Snapshot returns a read-only copy of the wrapped histogram.
+func (h *StandardAsyncHistogram) Snapshot() Histogram { return h.histogram.Snapshot() }
+
+// StdDev returns the standard deviation of the values in the sample.
+func (h *StandardAsyncHistogram) StdDev() float64 { return h.histogram.StdDev() }
+
+// Update queues a new value, or drops it if the buffer is full or the
+// histogram is closed.
+func (h *StandardAsyncHistogram) Update(v int64) {
+	h.mutex.RLock()
+	if h.stopped {
+		h.mutex.RUnlock()
+		h.dropped.Add(1)
+		return
+	}
+	select {
+	case h.updates <- v:
+	default:
+		h.dropped.Add(1)
+	}
+	h.mutex.RUnlock()
+}
+
+// Variance returns the variance of the values in the sample.
+func (h *StandardAsyncHistogram) Variance() float64 { return h.histogram.Variance() }
+
+// drain applies every update currently queued.
+func (h *StandardAsyncHistogram) drain() {
+	for {
+		select {
+		case v := <-h.updates:
+			h.histogram.Update(v)
+		default:
+			return
+		}
+	}
+}
+
+// run applies queued updates and answers flushes until the histogram is
+// closed or done is closed, then refuses further updates and applies those
+// still queued.
+func (h *StandardAsyncHistogram) run(done <-chan struct{}) {
+	defer close(h.closed)
+	for {
+		select {
+		case v := <-h.updates:
+			h.histogram.Update(v)
+		case c := <-h.flushes:
+			h.drain()
+			close(c)
+		case <-h.closing:
+			h.stop()
+			return
+		case <-done:
+			h.stop()
+			return
+		}
+	}
+}
+
+// stop refuses further updates and applies those already queued.
+func (h *StandardAsyncHistogram) stop() {
+	h.mutex.Lock()
+	h.stopped = true
+	h.mutex.Unlock()
+	h.drain()
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestAsyncHistogram(t *testing.T) {
+	h := NewAsyncHistogram(NewHistogram(NewUniformSample(100)), 10)
+	h.Update(1)
+	h.Update(47)
+	h.Flush()
+	if count := h.Count(); 2 != count {
+		t.Errorf("h.Count(): 2 != %v\n", count)
+	}
+	if max := h.Max(); 47 != max {
+		t.Errorf("h.Max(): 47 != %v\n", max)
+	}
+	h.Update(2)
+	h.Close()
+	if count := h.Count(); 3 != count {
+		t.Errorf("h.Count() after Close: 3 != %v\n", count)
+	}
+}
+
+func TestAsyncHistogramDropped(t *testing.T) {
+	h := newAsyncHistogram(NewHistogram(NewUniformSample(100)), 2)
+	for i := 0; i < 5; i++ {
+		h.Update(int64(i))
+	}
+	if dropped := h.Dropped(); 3 != dropped {
+		t.Errorf("h.Dropped(): 3 != %v\n", dropped)
+	}
+	go h.run(nil)
+	h.Flush()
+	if count := h.Count(); 2 != count {
+		t.Errorf("h.Count(): 2 != %v\n", count)
+	}
+	h.Close()
+}
+
+func TestAsyncHistogramClose(t *testing.T) {
+	h := NewAsyncHistogram(NewHistogram(NewUniformSample(100)), 10)
+	h.Update(1)
+	h.Close()
+	h.Close()
+	h.Update(2)
+	h.Flush()
+	if count := h.Count(); 1 != count {
+		t.Errorf("h.Count(): 1 != %v\n", count)
+	}
+	if dropped := h.Dropped(); 1 != dropped {
+		t.Errorf("h.Dropped(): 1 != %v\n", dropped)
+	}
+}
+
+func TestAsyncHistogramCloseRace(t *testing.T) {
+	h := NewAsyncHistogram(NewHistogram(NewUniformSample(100)), 10)
+	wg := &sync.WaitGroup{}
+	wg.Add(4)
+	for i := 0; i < 4; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				h.Update(int64(j))
+			}
+		}()
+	}
+	h.Close()
+	wg.Wait()
+	if n := h.Count() + h.Dropped(); 4000 != n {
+		t.Errorf("applied + dropped: 4000 != %v\n", n)
+	}
+}
+
+func TestAsyncHistogramLifecycle(t *testing.T) {
+	l := NewLifecycle()
+	h := NewAsyncHistogramWithLifecycle(NewHistogram(NewUniformSample(100)), 10, l)
+	h.Update(1)
+	l.StopAll()
+	h.Update(2)
+	h.Close()
+	if count, dropped := h.Count(), h.Dropped(); 1 != count || 1 != dropped {
+		t.Errorf("count %v dropped %v\n", count, dropped)
+	}
+}
+
+func TestAsyncHistogramNil(t *testing.T) {
+	UseNilMetrics = true
+	defer func() { UseNilMetrics = false }()
+	h := NewAsyncHistogram(NewHistogram(NewUniformSample(100)), 10)
+	if _, ok := h.(NilAsyncHistogram); !ok {
+		t.Fatalf("%T\n", h)
+	}
+	h.Update(1)
+	h.Flush()
+	h.Close()
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import "sync/atomic"
+
+// NewInFlightHistogram constructs a new InFlightHistogram from a Sample.
+func NewInFlightHistogram(s Sample) *InFlightHistogram {
+	if UseNilMetrics {
+		return &InFlightHistogram{histogram: NilHistogram{}}
+	}
+	return &InFlightHistogram{histogram: NewHistogram(s)}
+}
+
+// InFlightHistogram tracks the number of callers between Enter and Exit and
+// records, on every Exit, how many were in flight including the one
+// exiting, so its distribution describes the concurrency callers observed.
+// It is a Histogram, so it can be registered and reported, but only Exit
+// records values.
+type InFlightHistogram struct {
+	inFlight  atomic.Int64
+	histogram Histogram
+}
+
+// Clear clears the recorded samples.  It does not change the number of
+// callers in flight.
+func (h *InFlightHistogram) Clear() { h.histogram.Clear() }
+
+// Count returns the number of Exits recorded.
+func (h *InFlightHistogram) Count() int64 { return h.histogram.Count() }
+
+// Enter records a caller entering.
+func (h *InFlightHistogram) Enter() {
+	h.inFlight.Add(1)
+}
+
+// Exit records a caller exiting and samples the number in flight.
+func (h *InFlightHistogram) Exit() {
+	h.histogram.Update(h.inFlight.Add(-1) + 1)
+}
+
+// InFlight returns the number of callers currently in flight.
+func (h *InFlightHistogram) InFlight() int64 {
+	return h.inFlight.Load()
+}
+
+// Max returns the maximum value in the sample.
+func (h *InFlightHistogram) Max() int64 { return h.histogram.Max() }
+
+// Mean returns the mean of the values in the sample.
+func (h *InFlightHistogram) Mean() float64 { return h.histogram.Mean() }
+
+// Min returns the minimum value in the sample.
+func (h *InFlightHistogram) Min() int64 { return h.histogram.Min() }
+
+// Percentile returns an arbitrary percentile of the values in the sample.
+func (h *InFlightHistogram) Percentile(p float64) float64 {
+	return h.histogram.Percentile(p)
+}
+
+// Percentiles returns a slice of arbitrary percentiles of the values in the
+// sample.
+func (h *InFlightHistogram) Percentiles(ps []float64) []float64 {
+	return h.histogram.Percentiles(ps)
+}
+
+// Sample returns the Sample underlying the histogram.
+func (h *InFlightHistogram) Sample() Sample { return h.histogram.Sample() }
+
+// Snapshot returns a read-only copy of the histogram.
+func (h *InFlightHistogram) Snapshot() Histogram { return h.histogram.Snapshot() }
+
+// StdDev returns the standard deviation of the values in the sample.
+func (h *InFlightHistogram) StdDev() float64 { return h.histogram.StdDev() }
+
+// Update panics, since Exit is the only way to record a value.
+func (*InFlightHistogram) Update(int64) {
+	panic("Update called on an InFlightHistogram")
+}
+
+// Variance returns the variance of the values in the sample.
+func (h *InFlightHistogram) Variance() float64 { return h.histogram.Variance() }
@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestInFlightHistogram(t *testing.T) {
+	h := NewInFlightHistogram(NewUniformSample(100))
+	h.Enter()
+	h.Enter()
+	h.Enter()
+	if n := h.InFlight(); 3 != n {
+		t.Fatalf("h.InFlight(): 3 != %v\n", n)
+	}
+	h.Exit()
+	h.Exit()
+	h.Exit()
+	if n := h.InFlight(); 0 != n {
+		t.Fatalf("h.InFlight(): 0 != %v\n", n)
+	}
+	if count, min, max := h.Count(), h.Min(), h.Max(); 3 != count || 1 != min || 3 != max {
+		t.Fatalf("count %v min %v max %v\n", count, min, max)
+	}
+}
+
+func TestInFlightHistogramConcurrent(t *testing.T) {
+	h := NewInFlightHistogram(NewUniformSample(1000))
+	entered, release := &sync.WaitGroup{}, make(chan struct{})
+	wg := &sync.WaitGroup{}
+	entered.Add(10)
+	wg.Add(10)
+	for i := 0; i < 10; i++ {
+		go func() {
+			defer wg.Done()
+			h.Enter()
+			entered.Done()
+			<-release
+			h.Exit()
+		}()
+	}
+	entered.Wait()
+	close(release)
+	wg.Wait()
+	if count := h.Count(); 10 != count {
+		t.Errorf("h.Count(): 10 != %v\n", count)
+	}
+	if max := h.Max(); 10 != max {
+		t.Errorf("h.Max(): 10 != %v\n", max)
+	}
+	if min := h.Min(); 1 != min {
+		t.Errorf("h.Min(): 1 != %v\n", min)
+	}
+}
+
+func TestInFlightHistogramNil(t *testing.T) {
+	UseNilMetrics = true
+	defer func() { UseNilMetrics = false }()
+	h := NewInFlightHistogram(NewUniformSample(100))
+	h.Enter()
+	h.Exit()
+	if count := h.Count(); 0 != count {
+		t.Errorf("h.Count(): 0 != %v\n", count)
+	}
+}
+
+func TestInFlightHistogramRegistered(t *testing.T) {
+	r := NewRegistry().(*StandardRegistry)
+	h := NewInFlightHistogram(NewUniformSample(100))
+	if err := r.Register("inflight", h); nil != err {
+		t.Fatal(err)
+	}
+	h.Enter()
+	h.Exit()
+	if count := Dump(r)["inflight.count"]; "1" != count {
+		t.Errorf("inflight.count: 1 != %v\n", count)
+	}
+	if infos := r.List(); 1 != len(infos) || "histogram" != infos[0].Type {
+		t.Errorf("r.List(): %v\n", infos)
+	}
+	h.Clear()
+	if count := h.Count(); 0 != count {
+		t.Errorf("h.Count() after Clear: 0 != %v\n", count)
+	}
+}
@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// NewRotatingHistogram constructs a new RotatingHistogram from a Sample
+// which rotates every d duration and launches a goroutine.  Call Stop to end
+// the goroutine.
+func NewRotatingHistogram(s Sample, d time.Duration, onRotate func(Histogram)) Histogram {
+	return NewRotatingHistogramWithLifecycle(s, d, onRotate, nil)
+}
+
+// NewRotatingHistogramWithLifecycle constructs a new RotatingHistogram just
+// like NewRotatingHistogram but tracks its goroutine with l, if l is not nil.
+func NewRotatingHistogramWithLifecycle(s Sample, d time.Duration, onRotate func(Histogram), l *Lifecycle) Histogram {
+	if UseNilMetrics {
+		return NilHistogram{}
+	}
+	h := newRotatingHistogram(s, onRotate)
+	startTicking(d, l, h.done, h.rotate)
+	return h
+}
+
+// RotatingHistogram is a Histogram which, on an interval, hands a snapshot of
+// the interval just completed to a callback, for example to persist it, and
+// then clears itself.  No update is lost or counted twice across a rotation.
+type RotatingHistogram struct {
+	mutex     sync.Mutex
+	histogram Histogram
+	onRotate  func(Histogram)
+	done      chan struct{}
+}
+
+func newRotatingHistogram(s Sample, onRotate func(Histogram)) *RotatingHistogram {
+	return &RotatingHistogram{
+		histogram: NewHistogram(s),
+		onRotate:  onRotate,
+		done:      make(chan struct{}),
+	}
+}
+
+// Clear clears the histogram without rotating it.
+func (h *RotatingHistogram) Clear() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.histogram.Clear()
+}
+
+// Count returns the number of samples recorded since the last rotation.
+func (h *RotatingHistogram) Count() int64 { return h.histogram.Count() }
+
+// Max returns the maximum value in the sample.
+func (h *RotatingHistogram) Max() int64 { return h.histogram.Max() }
+
+// Mean returns the mean of the values in the sample.
+func (h *RotatingHistogram) Mean() float64 { return h.histogram.Mean() }
+
+// Min returns the minimum value in the sample.
+func (h *RotatingHistogram) Min() int64 { return h.histogram.Min() }
+
+// Percentile returns an arbitrary percentile of the values in the sample.
+func (h *RotatingHistogram) Percentile(p float64) float64 {
+	return h.histogram.Percentile(p)
+}
+
+// Percentiles returns a slice of arbitrary percentiles of the values in the
+// sample.
+func (h *RotatingHistogram) Percentiles(ps []float64) []float64 {
+	return h.histogram.Percentiles(ps)
+}
+
+// Sample returns the Sample underlying the histogram.
+func (h *RotatingHistogram) Sample() Sample { return h.histogram.Sample() }
+
+// Snapshot returns a read-only copy of the histogram.
+func (h *RotatingHistogram) Snapshot() Histogram { return h.histogram.Snapshot() }
+
+// StdDev returns the standard deviation of the values in the sample.
+func (h *RotatingHistogram) StdDev() float64 { return h.histogram.StdDev() }
+
+// Stop ends the goroutine rotating the histogram.  It must be called at most
+// once.
+func (h *RotatingHistogram) Stop() {
+	close(h.done)
+}
+
+// Update samples a new value.
+func (h *RotatingHistogram) Update(v int64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.histogram.Update(v)
+}
+
+// Variance returns the variance of the values in the sample.
+func (h *RotatingHistogram) Variance() float64 { return h.histogram.Variance() }
+
+func (h *RotatingHistogram) rotate() {
+	h.mutex.Lock()
+	snapshot := h.histogram.Snapshot()
+	h.histogram.Clear()
+	h.mutex.Unlock()
+	h.onRotate(snapshot)
+}
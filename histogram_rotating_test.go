@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRotatingHistogram(t *testing.T) {
+	var rotated []Histogram
+	h := newRotatingHistogram(NewUniformSample(100), func(s Histogram) {
+		rotated = append(rotated, s)
+	})
+	h.Update(1)
+	h.Update(2)
+	runTicking(ticks(1), h.done, nil, h.rotate)
+	h.Update(10)
+	runTicking(ticks(2), h.done, nil, h.rotate)
+
+	if 3 != len(rotated) {
+		t.Fatalf("3 != %v rotations\n", len(rotated))
+	}
+	if count, max := rotated[0].Count(), rotated[0].Max(); 2 != count || 2 != max {
+		t.Errorf("first interval: count %v max %v\n", count, max)
+	}
+	if count, max := rotated[1].Count(), rotated[1].Max(); 1 != count || 10 != max {
+		t.Errorf("second interval: count %v max %v\n", count, max)
+	}
+	if count := rotated[2].Count(); 0 != count {
+		t.Errorf("idle interval: count %v\n", count)
+	}
+	if count := h.Count(); 0 != count {
+		t.Errorf("h.Count(): 0 != %v\n", count)
+	}
+}
+
+func TestRotatingHistogramLifecycle(t *testing.T) {
+	l := NewLifecycle()
+	h := NewRotatingHistogramWithLifecycle(NewUniformSample(100), time.Hour, func(Histogram) {}, l)
+	h.Update(1)
+	l.StopAll()
+	if count := h.Count(); 1 != count {
+		t.Errorf("h.Count(): 1 != %v\n", count)
+	}
+}
@@ -10,6 +10,29 @@ func BenchmarkHistogram(b *testing.B) {
 	}
 }
 
+func TestAssertNoRegression(t *testing.T) {
+	baseline := NewHistogram(NewUniformSample(100))
+	candidate := NewHistogram(NewUniformSample(100))
+	for i := int64(1); i <= 100; i++ {
+		baseline.Update(i)
+		candidate.Update(i + 5)
+	}
+	if err := AssertNoRegression(baseline, candidate, 0.5, 10); nil != err {
+		t.Fatal(err)
+	}
+	candidate.Clear()
+	for i := int64(1); i <= 100; i++ {
+		candidate.Update(i * 2)
+	}
+	err := AssertNoRegression(baseline, candidate, 0.5, 10)
+	if nil == err {
+		t.Fatal("AssertNoRegression(): nil error on a regression")
+	}
+	if "0.5 percentile regressed: 101.00 > 55.55 (baseline 50.50 + 10%)" != err.Error() {
+		t.Fatal(err)
+	}
+}
+
 func TestGetOrRegisterHistogram(t *testing.T) {
 	r := NewRegistry()
 	s := NewUniformSample(100)
@@ -66,6 +89,33 @@ func TestHistogramSnapshot(t *testing.T) {
 	testHistogram10000(t, snapshot)
 }
 
+func TestHistogramZeroValue(t *testing.T) {
+	var h StandardHistogram
+	if count := h.Count(); 0 != count {
+		t.Errorf("h.Count(): 0 != %v\n", count)
+	}
+	h.Update(47)
+	if count := h.Count(); 1 != count {
+		t.Errorf("h.Count(): 1 != %v\n", count)
+	}
+	if max := h.Snapshot().Max(); 47 != max {
+		t.Errorf("h.Snapshot().Max(): 47 != %v\n", max)
+	}
+}
+
+func TestSafeUpdate(t *testing.T) {
+	h := NewHistogram(NewUniformSample(100))
+	if SafeUpdate(h, 47) {
+		t.Fatal("SafeUpdate(): recovered on a live histogram")
+	}
+	if count := h.Count(); 1 != count {
+		t.Fatalf("h.Count(): 1 != %v\n", count)
+	}
+	if !SafeUpdate(h.Snapshot(), 47) {
+		t.Fatal("SafeUpdate(): didn't recover on a snapshot")
+	}
+}
+
 func testHistogram10000(t *testing.T, h Histogram) {
 	if count := h.Count(); 10000 != count {
 		t.Errorf("h.Count(): 10000 != %v\n", count)
@@ -1,6 +1,9 @@
 package metrics
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"io"
+)
 
 // MarshalJSON returns a byte slice containing a JSON representation of all
 // the metrics in the Registry.
@@ -63,3 +66,9 @@ func (r StandardRegistry) MarshalJSON() ([]byte, error) {
 	})
 	return json.Marshal(data)
 }
+
+// WriteJSONOnce writes every metric in r to w once as JSON, followed by a
+// newline.
+func WriteJSONOnce(r Registry, w io.Writer) error {
+	return json.NewEncoder(w).Encode(r)
+}
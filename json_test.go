@@ -3,7 +3,9 @@ package metrics
 import (
 	"bytes"
 	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestRegistryMarshallJSON(t *testing.T) {
@@ -16,3 +18,22 @@ func TestRegistryMarshallJSON(t *testing.T) {
 		t.Fatalf(s)
 	}
 }
+
+func TestRegistryMarshallJSONEmptyMeter(t *testing.T) {
+	r := NewRegistry()
+	r.Register("timer", NewTimer())
+	m := newStandardMeter()
+	m.now = func() time.Time { return m.startTime }
+	m.tick()
+	r.Register("meter", m)
+	b, err := json.Marshal(r)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "NaN") {
+		t.Fatal(string(b))
+	}
+	if rateMean := m.RateMean(); 0 != rateMean {
+		t.Errorf("m.RateMean(): 0 != %v\n", rateMean)
+	}
+}
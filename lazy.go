@@ -0,0 +1,167 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// NewLazyMetric constructs the metric returned by factory but registers it
+// in r under the given name only when it is first written to, so metrics
+// for rare events stay out of reports until they happen.  The returned
+// proxy implements the same metric interface as the constructed metric and
+// reads zero values before the first write.  If the name is already taken
+// by a metric of the same kind when the proxy is first written to, writes
+// go to that metric instead, while reads through the proxy keep seeing the
+// metric factory constructed.  Metrics other than counters, gauges,
+// histograms, meters and timers are registered immediately, and the
+// metric already registered under the name, if any, is returned instead.
+func NewLazyMetric(r Registry, name string, factory func() interface{}) interface{} {
+	if nil == r {
+		r = DefaultRegistry
+	}
+	i := factory()
+	l := &lazyRegistration{registry: r, name: name, metric: i}
+	switch metric := i.(type) {
+	case Counter:
+		return &lazyCounter{metric, l}
+	case Gauge:
+		return &lazyGauge{metric, l}
+	case GaugeFloat64:
+		return &lazyGaugeFloat64{metric, l}
+	case Histogram:
+		return &lazyHistogram{metric, l}
+	case Meter:
+		return &lazyMeter{metric, l}
+	case Timer:
+		return &lazyTimer{metric, l}
+	}
+	return r.GetOrRegister(name, i)
+}
+
+// lazyRegistration registers a metric at most once, on its first write, and
+// remembers the metric the registry holds under its name afterwards.
+type lazyRegistration struct {
+	once       sync.Once
+	registry   Registry
+	name       string
+	metric     interface{}
+	registered interface{}
+}
+
+// register registers the metric if it has not been registered yet and
+// returns the metric registered under its name, which is a different one
+// if the name was already taken.
+func (l *lazyRegistration) register() interface{} {
+	l.once.Do(func() {
+		l.registered = l.registry.GetOrRegister(l.name, l.metric)
+	})
+	return l.registered
+}
+
+type lazyCounter struct {
+	Counter
+	lazy *lazyRegistration
+}
+
+func (c *lazyCounter) Dec(i int64) {
+	c.target().Dec(i)
+}
+
+func (c *lazyCounter) Inc(i int64) {
+	c.target().Inc(i)
+}
+
+func (c *lazyCounter) target() Counter {
+	if m, ok := c.lazy.register().(Counter); ok {
+		return m
+	}
+	return c.Counter
+}
+
+type lazyGauge struct {
+	Gauge
+	lazy *lazyRegistration
+}
+
+func (g *lazyGauge) Update(v int64) {
+	g.target().Update(v)
+}
+
+func (g *lazyGauge) target() Gauge {
+	if m, ok := g.lazy.register().(Gauge); ok {
+		return m
+	}
+	return g.Gauge
+}
+
+type lazyGaugeFloat64 struct {
+	GaugeFloat64
+	lazy *lazyRegistration
+}
+
+func (g *lazyGaugeFloat64) Update(v float64) {
+	g.target().Update(v)
+}
+
+func (g *lazyGaugeFloat64) target() GaugeFloat64 {
+	if m, ok := g.lazy.register().(GaugeFloat64); ok {
+		return m
+	}
+	return g.GaugeFloat64
+}
+
+type lazyHistogram struct {
+	Histogram
+	lazy *lazyRegistration
+}
+
+func (h *lazyHistogram) Update(v int64) {
+	h.target().Update(v)
+}
+
+func (h *lazyHistogram) target() Histogram {
+	if m, ok := h.lazy.register().(Histogram); ok {
+		return m
+	}
+	return h.Histogram
+}
+
+type lazyMeter struct {
+	Meter
+	lazy *lazyRegistration
+}
+
+func (m *lazyMeter) Mark(n int64) {
+	m.target().Mark(n)
+}
+
+func (m *lazyMeter) target() Meter {
+	if registered, ok := m.lazy.register().(Meter); ok {
+		return registered
+	}
+	return m.Meter
+}
+
+type lazyTimer struct {
+	Timer
+	lazy *lazyRegistration
+}
+
+func (t *lazyTimer) target() Timer {
+	if m, ok := t.lazy.register().(Timer); ok {
+		return m
+	}
+	return t.Timer
+}
+
+func (t *lazyTimer) Time(f func()) {
+	t.target().Time(f)
+}
+
+func (t *lazyTimer) Update(d time.Duration) {
+	t.target().Update(d)
+}
+
+func (t *lazyTimer) UpdateSince(ts time.Time) {
+	t.target().UpdateSince(ts)
+}
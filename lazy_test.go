@@ -0,0 +1,63 @@
+package metrics
+
+import "testing"
+
+func TestLazyMetric(t *testing.T) {
+	r := NewRegistry()
+	c := NewLazyMetric(r, "errors", func() interface{} { return NewCounter() }).(Counter)
+	if count := c.Count(); 0 != count {
+		t.Fatalf("c.Count(): 0 != %v\n", count)
+	}
+	if m := r.Get("errors"); nil != m {
+		t.Fatalf("registered before first write: %v\n", m)
+	}
+	c.Inc(1)
+	c.Inc(1)
+	if count := r.Get("errors").(Counter).Count(); 2 != count {
+		t.Fatalf("errors: 2 != %v\n", count)
+	}
+}
+
+func TestLazyMetricTimer(t *testing.T) {
+	r := NewRegistry()
+	tm := NewLazyMetric(r, "slow", func() interface{} { return NewTimer() }).(Timer)
+	if m := r.Get("slow"); nil != m {
+		t.Fatalf("registered before first write: %v\n", m)
+	}
+	tm.Time(func() {})
+	if count := r.Get("slow").(Timer).Count(); 1 != count {
+		t.Fatalf("slow: 1 != %v\n", count)
+	}
+}
+
+func TestLazyMetricUnknown(t *testing.T) {
+	r := NewRegistry()
+	NewLazyMetric(r, "healthcheck", func() interface{} { return NewHealthcheck(func(Healthcheck) {}) })
+	if m := r.Get("healthcheck"); nil == m {
+		t.Fatal("healthcheck not registered immediately")
+	}
+}
+
+func TestLazyMetricCollision(t *testing.T) {
+	r := NewRegistry()
+	existing := NewCounter()
+	c := NewLazyMetric(r, "errors", func() interface{} { return NewCounter() }).(Counter)
+	r.Register("errors", existing)
+	c.Inc(1)
+	if m := r.Get("errors"); existing != m {
+		t.Fatalf("errors replaced: %v\n", m)
+	}
+	if count := existing.Count(); 1 != count {
+		t.Errorf("existing.Count(): 1 != %v\n", count)
+	}
+}
+
+func TestLazyMetricUnknownCollision(t *testing.T) {
+	r := NewRegistry()
+	existing := NewHealthcheck(func(Healthcheck) {})
+	r.Register("healthcheck", existing)
+	h := NewLazyMetric(r, "healthcheck", func() interface{} { return NewHealthcheck(func(Healthcheck) {}) })
+	if existing != h {
+		t.Errorf("NewLazyMetric: %v != %v\n", existing, h)
+	}
+}
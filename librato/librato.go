@@ -1,8 +1,10 @@
 package librato
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/rcrowley/go-metrics"
+	"io"
 	"log"
 	"math"
 	"regexp"
@@ -52,6 +54,15 @@ func (self *Reporter) Run() {
 	}
 }
 
+// WriteOnce writes the JSON body Run would post for r to w once.
+func (self *Reporter) WriteOnce(r metrics.Registry, w io.Writer) error {
+	batch, err := self.BuildRequest(time.Now(), r)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(batch)
+}
+
 // calculate sum of squares from data provided by metrics.Histogram
 // see http://en.wikipedia.org/wiki/Standard_deviation#Rapid_calculation_methods
 func sumSquares(s metrics.Sample) float64 {
@@ -0,0 +1,29 @@
+package librato
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+func TestReporterWriteOnce(t *testing.T) {
+	r := metrics.NewRegistry()
+	metrics.NewRegisteredCounter("foo", r).Inc(47)
+	reporter := NewReporter(r, time.Minute, "email", "token", "source", nil, time.Millisecond)
+	s, err := metrics.CaptureReport(r, reporter.WriteOnce)
+	if nil != err {
+		t.Fatal(err)
+	}
+	var batch Batch
+	if err := json.Unmarshal([]byte(s), &batch); nil != err {
+		t.Fatal(err)
+	}
+	if "source" != batch.Source || 1 != len(batch.Counters) {
+		t.Fatal(s)
+	}
+	if name, value := batch.Counters[0][Name], batch.Counters[0][Value]; "foo.count" != name || 47.0 != value {
+		t.Fatal(s)
+	}
+}
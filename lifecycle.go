@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// Lifecycle tracks background goroutines, such as those recomputing
+// gauges, so that they can all be stopped and joined together.
+type Lifecycle struct {
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+// NewLifecycle constructs a new Lifecycle.
+func NewLifecycle() *Lifecycle {
+	return &Lifecycle{done: make(chan struct{})}
+}
+
+// Go runs f in a new goroutine tracked by the lifecycle.  f must return
+// promptly once done is closed.
+func (l *Lifecycle) Go(f func(done <-chan struct{})) {
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		f(l.done)
+	}()
+}
+
+// StopAll signals every goroutine started by Go to stop and waits for them
+// to return.
+func (l *Lifecycle) StopAll() {
+	l.once.Do(func() { close(l.done) })
+	l.wg.Wait()
+}
+
+// runTicking calls f on every tick from c until c is closed or either stop
+// or done is closed.
+func runTicking(c <-chan time.Time, stop, done <-chan struct{}, f func()) {
+	for {
+		select {
+		case _, ok := <-c:
+			if !ok {
+				return
+			}
+			f()
+		case <-stop:
+			return
+		case <-done:
+			return
+		}
+	}
+}
+
+// startTicking launches a goroutine calling f every d duration until stop is
+// closed, tracked by l if l is not nil.
+func startTicking(d time.Duration, l *Lifecycle, stop <-chan struct{}, f func()) {
+	ticker := time.NewTicker(d)
+	run := func(done <-chan struct{}) {
+		defer ticker.Stop()
+		runTicking(ticker.C, stop, done, f)
+	}
+	if nil == l {
+		go run(nil)
+	} else {
+		l.Go(run)
+	}
+}
@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLifecycleStopAll(t *testing.T) {
+	l := NewLifecycle()
+	stopped := int64(0)
+	for i := 0; i < 10; i++ {
+		l.Go(func(done <-chan struct{}) {
+			<-done
+			atomic.AddInt64(&stopped, 1)
+		})
+	}
+	g := NewComputedGaugeWithLifecycle(time.Hour, func() int64 { return 47 }, l)
+	p := NewPercentileGaugeWithLifecycle(NewHistogram(NewUniformSample(100)), 0.5, time.Hour, l)
+	l.StopAll()
+	if n := atomic.LoadInt64(&stopped); 10 != n {
+		t.Fatalf("stopped: 10 != %v\n", n)
+	}
+	if v := g.Value(); 47 != v {
+		t.Fatalf("g.Value(): 47 != %v\n", v)
+	}
+	if v := p.Value(); 0 != v {
+		t.Fatalf("p.Value(): 0 != %v\n", v)
+	}
+	l.StopAll()
+}
+
+func TestLifecycleGoAfterStopAll(t *testing.T) {
+	l := NewLifecycle()
+	l.StopAll()
+	ran := false
+	l.Go(func(done <-chan struct{}) {
+		<-done
+		ran = true
+	})
+	l.StopAll()
+	if !ran {
+		t.Fatal("task started after StopAll didn't run")
+	}
+}
@@ -116,6 +116,7 @@ type StandardMeter struct {
 	snapshot    *MeterSnapshot
 	a1, a5, a15 EWMA
 	startTime   time.Time
+	now         func() time.Time
 }
 
 func newStandardMeter() *StandardMeter {
@@ -125,6 +126,7 @@ func newStandardMeter() *StandardMeter {
 		a5:        NewEWMA5(),
 		a15:       NewEWMA15(),
 		startTime: time.Now(),
+		now:       time.Now,
 	}
 }
 
@@ -193,7 +195,11 @@ func (m *StandardMeter) updateSnapshot() {
 	snapshot.rate1 = m.a1.Rate()
 	snapshot.rate5 = m.a5.Rate()
 	snapshot.rate15 = m.a15.Rate()
-	snapshot.rateMean = float64(snapshot.count) / time.Since(m.startTime).Seconds()
+	// guard against 0/0, which yields a NaN that encoding/json rejects
+	snapshot.rateMean = 0.0
+	if elapsed := m.now().Sub(m.startTime).Seconds(); 0 != snapshot.count && 0 < elapsed {
+		snapshot.rateMean = float64(snapshot.count) / elapsed
+	}
 }
 
 func (m *StandardMeter) tick() {
@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// NewFastDecayMeter constructs a new FastDecayMeter whose rate decays with
+// the given half-life.  It panics if halfLife is not positive.
+func NewFastDecayMeter(halfLife time.Duration) Meter {
+	if 0 >= halfLife {
+		panic("NewFastDecayMeter called with a non-positive half-life")
+	}
+	if UseNilMetrics {
+		return NilMeter{}
+	}
+	now := time.Now()
+	return &FastDecayMeter{
+		halfLife:  halfLife,
+		startTime: now,
+		lastTime:  now,
+		now:       time.Now,
+	}
+}
+
+// FastDecayMeter is a Meter whose rate is an exponentially-weighted moving
+// average with a configurable half-life, decayed continuously rather than on
+// the five-second ticks that drive StandardMeter.  A stream that stops
+// reads as (near) zero after a few half-lives instead of after many minutes.
+// Rate1, Rate5, and Rate15 all return this one rate.
+type FastDecayMeter struct {
+	mutex     sync.Mutex
+	count     int64
+	decayed   float64 // count of events, each weighted by its age
+	halfLife  time.Duration
+	startTime time.Time
+	lastTime  time.Time
+	now       func() time.Time
+}
+
+// Count returns the number of events recorded.
+func (m *FastDecayMeter) Count() int64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.count
+}
+
+// Mark records the occurance of n events.
+func (m *FastDecayMeter) Mark(n int64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.decay()
+	m.count += n
+	m.decayed += float64(n)
+}
+
+// Rate1 returns the decaying rate of events per second.
+func (m *FastDecayMeter) Rate1() float64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.rate()
+}
+
+// Rate5 returns the decaying rate of events per second.
+func (m *FastDecayMeter) Rate5() float64 {
+	return m.Rate1()
+}
+
+// Rate15 returns the decaying rate of events per second.
+func (m *FastDecayMeter) Rate15() float64 {
+	return m.Rate1()
+}
+
+// RateMean returns the meter's mean rate of events per second.
+func (m *FastDecayMeter) RateMean() float64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.rateMean()
+}
+
+// Snapshot returns a read-only copy of the meter.
+func (m *FastDecayMeter) Snapshot() Meter {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	rate := m.rate()
+	return &MeterSnapshot{
+		count:    m.count,
+		rate1:    rate,
+		rate5:    rate,
+		rate15:   rate,
+		rateMean: m.rateMean(),
+	}
+}
+
+// decay ages the weighted count to the current time.  It should run with
+// m.mutex held.
+func (m *FastDecayMeter) decay() {
+	now := m.now()
+	if elapsed := now.Sub(m.lastTime); 0 < elapsed {
+		m.decayed *= math.Exp2(-float64(elapsed) / float64(m.halfLife))
+	}
+	m.lastTime = now
+}
+
+// rate converts the weighted count into events per second: a steady stream
+// of r events per second holds the weighted count at r*halfLife/ln(2).  It
+// should run with m.mutex held.
+func (m *FastDecayMeter) rate() float64 {
+	m.decay()
+	return m.decayed * math.Ln2 / m.halfLife.Seconds()
+}
+
+// rateMean should run with m.mutex held.
+func (m *FastDecayMeter) rateMean() float64 {
+	elapsed := m.now().Sub(m.startTime).Seconds()
+	if 0 >= elapsed {
+		return 0.0
+	}
+	return float64(m.count) / elapsed
+}
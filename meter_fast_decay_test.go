@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFastDecayMeter(t *testing.T) {
+	now := time.Unix(0, 0)
+	m := NewFastDecayMeter(time.Second).(*FastDecayMeter)
+	m.now = func() time.Time { return now }
+	m.startTime, m.lastTime = now, now
+
+	// A steady 100 events per second converges on a rate of 100.
+	for i := 0; i < 2000; i++ {
+		now = now.Add(10 * time.Millisecond)
+		m.Mark(1)
+	}
+	if rate := m.Rate1(); math.Abs(rate-100) > 5 {
+		t.Fatalf("m.Rate1(): 100 != %v\n", rate)
+	}
+
+	// Each idle half-life halves the rate.
+	rate := m.Rate1()
+	now = now.Add(time.Second)
+	if halved := m.Rate1(); math.Abs(halved-rate/2) > 1e-9 {
+		t.Fatalf("m.Rate1(): %v != %v\n", rate/2, halved)
+	}
+	now = now.Add(10 * time.Second)
+	if rate := m.Rate1(); rate > 0.1 {
+		t.Fatalf("m.Rate1() after idling: %v > 0.1\n", rate)
+	}
+	if snapshot := m.Snapshot(); 2000 != snapshot.Count() || snapshot.Rate15() != m.Rate1() {
+		t.Fatal(snapshot)
+	}
+	if rateMean := m.RateMean(); 2000.0/31.0 != rateMean {
+		t.Fatalf("m.RateMean(): %v != %v\n", 2000.0/31.0, rateMean)
+	}
+}
+
+func TestFastDecayMeterZero(t *testing.T) {
+	m := NewFastDecayMeter(time.Second)
+	if rate := m.Rate1(); 0.0 != rate {
+		t.Errorf("m.Rate1(): 0.0 != %v\n", rate)
+	}
+	if count := m.Count(); 0 != count {
+		t.Errorf("m.Count(): 0 != %v\n", count)
+	}
+}
+
+func TestFastDecayMeterNonPositiveHalfLife(t *testing.T) {
+	for _, halfLife := range []time.Duration{0, -time.Second} {
+		func() {
+			defer func() {
+				if nil == recover() {
+					t.Errorf("NewFastDecayMeter(%v) did not panic\n", halfLife)
+				}
+			}()
+			NewFastDecayMeter(halfLife)
+		}()
+	}
+}
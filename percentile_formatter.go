@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// A PercentileFormatter returns the label a reporter uses for the given
+// percentile, where p is between 0 and 1.  Graphite and OpenTSDB use the
+// label as a single component of a dotted metric name, so formatters for
+// them must not return dots.
+type PercentileFormatter func(p float64) string
+
+// GraphitePercentileFormatter labels percentiles as Graphite and OpenTSDB
+// reporters always have, e.g. "99-percentile" and "999-percentile".
+func GraphitePercentileFormatter(p float64) string {
+	return strings.Replace(percent(p), ".", "", -1) + "-percentile"
+}
+
+// PrometheusPercentileFormatter labels percentiles as the value of the
+// Prometheus quantile label, e.g. "0.99" and "0.999".
+func PrometheusPercentileFormatter(p float64) string {
+	return strconv.FormatFloat(p, 'g', -1, 64)
+}
+
+// StatsDPercentileFormatter labels percentiles in StatsD style, e.g. "p99"
+// and "p99_9".
+func StatsDPercentileFormatter(p float64) string {
+	return "p" + strings.Replace(percent(p), ".", "_", -1)
+}
+
+// percent formats p as a percentage, rounded to four decimal places to
+// hide floating-point noise such as 0.999*100 != 99.9.
+func percent(p float64) string {
+	return strconv.FormatFloat(math.Floor(p*1e6+0.5)/1e4, 'f', -1, 64)
+}
@@ -0,0 +1,24 @@
+package metrics
+
+import "testing"
+
+func TestPercentileFormatters(t *testing.T) {
+	for _, c := range []struct {
+		f        PercentileFormatter
+		p        float64
+		expected string
+	}{
+		{GraphitePercentileFormatter, 0.5, "50-percentile"},
+		{GraphitePercentileFormatter, 0.99, "99-percentile"},
+		{GraphitePercentileFormatter, 0.999, "999-percentile"},
+		{PrometheusPercentileFormatter, 0.5, "0.5"},
+		{PrometheusPercentileFormatter, 0.999, "0.999"},
+		{StatsDPercentileFormatter, 0.5, "p50"},
+		{StatsDPercentileFormatter, 0.99, "p99"},
+		{StatsDPercentileFormatter, 0.999, "p99_9"},
+	} {
+		if label := c.f(c.p); c.expected != label {
+			t.Errorf("%v: %s != %s\n", c.p, c.expected, label)
+		}
+	}
+}
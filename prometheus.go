@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// a regexp matching the characters not allowed in Prometheus metric names
+var prometheusInvalidRegexp = regexp.MustCompile("[^a-zA-Z0-9_:]")
+
+// pushgatewayClient bounds each push so that a stuck pushgateway cannot hang
+// a batch job on its way out.
+var pushgatewayClient = &http.Client{Timeout: 30 * time.Second}
+
+// PushToGateway serializes every metric in r in the Prometheus text format
+// and PUTs it to the pushgateway at url under the given job and grouping
+// labels, replacing whatever was previously pushed for that group.
+// Histograms and timers are pushed as summaries.  The push times out after
+// 30 seconds.
+func PushToGateway(r Registry, url, job string, grouping map[string]string) error {
+	b := &bytes.Buffer{}
+	if err := WritePrometheus(r, b); nil != err {
+		return err
+	}
+	req, err := http.NewRequest("PUT", pushgatewayURL(url, job, grouping), b)
+	if nil != err {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	resp, err := pushgatewayClient.Do(req)
+	if nil != err {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("pushgateway returned %s", resp.Status)
+	}
+	return nil
+}
+
+func pushgatewayURL(base, job string, grouping map[string]string) string {
+	path := []string{strings.TrimSuffix(base, "/"), "metrics", "job", url.PathEscape(job)}
+	names := make([]string, 0, len(grouping))
+	for name, _ := range grouping {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path = append(path, url.PathEscape(name), url.PathEscape(grouping[name]))
+	}
+	return strings.Join(path, "/")
+}
+
+// prometheusName replaces the characters not allowed in Prometheus metric
+// names with underscores and prefixes names which start with a digit.
+func prometheusName(name string) string {
+	pname := prometheusInvalidRegexp.ReplaceAllString(name, "_")
+	if "" == pname || ('0' <= pname[0] && pname[0] <= '9') {
+		pname = "_" + pname
+	}
+	return pname
+}
+
+// WritePrometheus writes every metric in r to w in the Prometheus text format,
+// as PushToGateway sends it.  It writes nothing and returns an error if two
+// metrics map to the same Prometheus name, since the duplicate series would
+// be rejected.  Quantiles are labeled by PrometheusPercentileFormatter.
+func WritePrometheus(r Registry, w io.Writer) error {
+	return WritePrometheusWithFormatter(r, w, PrometheusPercentileFormatter)
+}
+
+// WritePrometheusWithFormatter is like WritePrometheus but labels the
+// quantiles of histograms and timers with the value pf returns.
+func WritePrometheusWithFormatter(r Registry, w io.Writer, pf PercentileFormatter) error {
+	metrics := make(map[string]interface{})
+	r.Each(func(name string, i interface{}) {
+		metrics[name] = i
+	})
+	names := make([]string, 0, len(metrics))
+	for name, _ := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pnames := make([]string, len(names))
+	seen := make(map[string]string, len(names))
+	for j, name := range names {
+		pnames[j] = prometheusName(name)
+		if other, ok := seen[pnames[j]]; ok {
+			return fmt.Errorf("metrics %q and %q both have the Prometheus name %q", other, name, pnames[j])
+		}
+		seen[pnames[j]] = name
+	}
+	for j, name := range names {
+		pname := pnames[j]
+		switch metric := metrics[name].(type) {
+		case Counter:
+			// Counters can decrement, which a Prometheus counter cannot.
+			fmt.Fprintf(w, "# TYPE %s gauge\n", pname)
+			fmt.Fprintf(w, "%s %d\n", pname, metric.Count())
+		case Gauge:
+			fmt.Fprintf(w, "# TYPE %s gauge\n", pname)
+			fmt.Fprintf(w, "%s %d\n", pname, metric.Value())
+		case GaugeFloat64:
+			fmt.Fprintf(w, "# TYPE %s gauge\n", pname)
+			fmt.Fprintf(w, "%s %g\n", pname, metric.Value())
+		case Histogram:
+			h := metric.Snapshot()
+			writePrometheusSummary(w, pname, h.Count(), h.Mean(), h.Percentiles, pf)
+		case Meter:
+			m := metric.Snapshot()
+			fmt.Fprintf(w, "# TYPE %s counter\n", pname)
+			fmt.Fprintf(w, "%s %d\n", pname, m.Count())
+		case Timer:
+			t := metric.Snapshot()
+			writePrometheusSummary(w, pname, t.Count(), t.Mean(), t.Percentiles, pf)
+		}
+	}
+	return nil
+}
+
+func writePrometheusSummary(w io.Writer, name string, count int64, mean float64, percentiles func([]float64) []float64, pf PercentileFormatter) {
+	qs := []float64{0.5, 0.75, 0.95, 0.99, 0.999}
+	ps := percentiles(qs)
+	fmt.Fprintf(w, "# TYPE %s summary\n", name)
+	for i, q := range qs {
+		fmt.Fprintf(w, "%s{quantile=\"%s\"} %g\n", name, pf(q), ps[i])
+	}
+	fmt.Fprintf(w, "%s_sum %g\n", name, mean*float64(count))
+	fmt.Fprintf(w, "%s_count %d\n", name, count)
+}
@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPushToGateway(t *testing.T) {
+	var method, path, body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, _ := ioutil.ReadAll(req.Body)
+		method, path, body = req.Method, req.URL.EscapedPath(), string(b)
+	}))
+	defer ts.Close()
+
+	r := NewRegistry()
+	NewRegisteredCounter("requests.count", r).Inc(3)
+	NewRegisteredGaugeFloat64("load", r).Update(0.5)
+	h := NewRegisteredHistogram("latency", r, NewUniformSample(100))
+	h.Update(10)
+	h.Update(20)
+	if err := PushToGateway(r, ts.URL+"/", "batch job", map[string]string{"instance": "a", "az": "b"}); nil != err {
+		t.Fatal(err)
+	}
+
+	if "PUT" != method {
+		t.Fatal(method)
+	}
+	if "/metrics/job/batch%20job/az/b/instance/a" != path {
+		t.Fatal(path)
+	}
+	expected := `# TYPE latency summary
+latency{quantile="0.5"} 15
+latency{quantile="0.75"} 20
+latency{quantile="0.95"} 20
+latency{quantile="0.99"} 20
+latency{quantile="0.999"} 20
+latency_sum 30
+latency_count 2
+# TYPE load gauge
+load 0.5
+# TYPE requests_count gauge
+requests_count 3
+`
+	if expected != body {
+		t.Fatal(body)
+	}
+}
+
+func TestPushToGatewayError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer ts.Close()
+	if err := PushToGateway(NewRegistry(), ts.URL, "job", nil); nil == err {
+		t.Fatal("PushToGateway(): nil error on 400 response")
+	}
+}
+
+func TestPushToGatewayNameCollision(t *testing.T) {
+	pushed := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		pushed = true
+	}))
+	defer ts.Close()
+	r := NewRegistry()
+	NewRegisteredCounter("a.b", r)
+	NewRegisteredCounter("a_b", r)
+	if err := PushToGateway(r, ts.URL, "job", nil); nil == err {
+		t.Fatal("PushToGateway(): nil error on colliding names")
+	}
+	if pushed {
+		t.Fatal("PushToGateway() pushed colliding names")
+	}
+}
+
+func TestPrometheusName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"requests.count": "requests_count",
+		"5xx":            "_5xx",
+		"a:b":            "a:b",
+	} {
+		if pname := prometheusName(name); expected != pname {
+			t.Errorf("%s: %s != %s\n", name, expected, pname)
+		}
+	}
+}
+
+func TestWritePrometheusWithFormatter(t *testing.T) {
+	r := NewRegistry()
+	NewRegisteredHistogram("latency", r, NewUniformSample(100)).Update(10)
+	b := &bytes.Buffer{}
+	if err := WritePrometheusWithFormatter(r, b, StatsDPercentileFormatter); nil != err {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "latency{quantile=\"p99_9\"} 10\n") {
+		t.Fatal(b.String())
+	}
+}
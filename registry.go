@@ -3,6 +3,7 @@ package metrics
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"sync"
 )
 
@@ -15,6 +16,14 @@ func (err DuplicateMetric) Error() string {
 	return fmt.Sprintf("duplicate metric: %s", string(err))
 }
 
+// UnknownMetric is the error returned by StandardRegistry.Rename when no
+// metric is registered under the old name.
+type UnknownMetric string
+
+func (err UnknownMetric) Error() string {
+	return fmt.Sprintf("unknown metric: %s", string(err))
+}
+
 // A Registry holds references to a set of metrics by name and can iterate
 // over them, calling callback functions provided by the user.
 //
@@ -43,16 +52,48 @@ type Registry interface {
 	Unregister(string)
 }
 
+// MetricInfo describes a registered metric for catalogs and other
+// introspection.  Description and Unit are empty unless set by Describe.
+type MetricInfo struct {
+	Name        string
+	Type        string
+	Description string
+	Unit        string
+}
+
 // The standard implementation of a Registry is a mutex-protected map
 // of names to metrics.
 type StandardRegistry struct {
-	metrics map[string]interface{}
-	mutex   sync.Mutex
+	metrics      map[string]interface{}
+	descriptions map[string]MetricInfo
+	mutex        sync.Mutex
 }
 
 // Create a new registry.
 func NewRegistry() Registry {
-	return &StandardRegistry{metrics: make(map[string]interface{})}
+	return &StandardRegistry{
+		metrics:      make(map[string]interface{}),
+		descriptions: make(map[string]MetricInfo),
+	}
+}
+
+// ClearHistograms clears every registered Histogram, leaving all other
+// metrics untouched, so latency can be reported per interval alongside
+// cumulative counters.
+func (r *StandardRegistry) ClearHistograms() {
+	for _, i := range r.registered() {
+		if h, ok := i.(Histogram); ok {
+			h.Clear()
+		}
+	}
+}
+
+// Describe attaches a human-readable description and unit to the metric with
+// the given name, to be reported by List.
+func (r *StandardRegistry) Describe(name, description, unit string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.descriptions[name] = MetricInfo{Description: description, Unit: unit}
 }
 
 // Call the given function for each registered metric.
@@ -86,6 +127,34 @@ func (r *StandardRegistry) GetOrRegister(name string, i interface{}) interface{}
 	return i
 }
 
+// GetOrRegisterOnce returns the metric registered under the given name or, if
+// there is none, registers and returns the metric constructed by factory.
+// factory is called at most once per name no matter how many goroutines race
+// to register it, which makes this safe for init functions that may run more
+// than once, as in tests.  The promise holds only for the metric types the
+// registry stores; any other value factory returns is returned unregistered,
+// so factory is called again on the next call.  factory runs with the
+// registry locked, so it must not call Register, GetOrRegister, or
+// NewRegistered* on the same registry; doing so deadlocks.
+func (r *StandardRegistry) GetOrRegisterOnce(name string, factory func() interface{}) interface{} {
+	return r.GetOrRegister(name, factory)
+}
+
+// List returns the name and type of every registered metric, sorted by name.
+func (r *StandardRegistry) List() []MetricInfo {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	infos := make([]MetricInfo, 0, len(r.metrics))
+	for name, i := range r.metrics {
+		info := r.descriptions[name]
+		info.Name = name
+		info.Type = metricType(i)
+		infos = append(infos, info)
+	}
+	sort.Sort(metricInfos(infos))
+	return infos
+}
+
 // Register the given metric under the given name.  Returns a DuplicateMetric
 // if a metric by the given name is already registered.
 func (r *StandardRegistry) Register(name string, i interface{}) error {
@@ -94,6 +163,29 @@ func (r *StandardRegistry) Register(name string, i interface{}) error {
 	return r.register(name, i)
 }
 
+// Rename moves the metric registered under oldName, along with its data and
+// description, to newName.  Returns an UnknownMetric if nothing is registered
+// under oldName or a DuplicateMetric if something is already registered under
+// newName.
+func (r *StandardRegistry) Rename(oldName, newName string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	metric, ok := r.metrics[oldName]
+	if !ok {
+		return UnknownMetric(oldName)
+	}
+	if _, ok := r.metrics[newName]; ok {
+		return DuplicateMetric(newName)
+	}
+	r.metrics[newName] = metric
+	delete(r.metrics, oldName)
+	if info, ok := r.descriptions[oldName]; ok {
+		r.descriptions[newName] = info
+		delete(r.descriptions, oldName)
+	}
+	return nil
+}
+
 // Run all registered healthchecks.
 func (r *StandardRegistry) RunHealthchecks() {
 	r.mutex.Lock()
@@ -110,6 +202,7 @@ func (r *StandardRegistry) Unregister(name string) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	delete(r.metrics, name)
+	delete(r.descriptions, name)
 }
 
 func (r *StandardRegistry) register(name string, i interface{}) error {
@@ -133,6 +226,33 @@ func (r *StandardRegistry) registered() map[string]interface{} {
 	return metrics
 }
 
+type metricInfos []MetricInfo
+
+func (m metricInfos) Len() int           { return len(m) }
+func (m metricInfos) Less(i, j int) bool { return m[i].Name < m[j].Name }
+func (m metricInfos) Swap(i, j int)      { m[i], m[j] = m[j], m[i] }
+
+// metricType returns the type tag reported by List for the given metric.
+func metricType(i interface{}) string {
+	switch i.(type) {
+	case Counter:
+		return "counter"
+	case Gauge:
+		return "gauge"
+	case GaugeFloat64:
+		return "gaugefloat64"
+	case Healthcheck:
+		return "healthcheck"
+	case Histogram:
+		return "histogram"
+	case Meter:
+		return "meter"
+	case Timer:
+		return "timer"
+	}
+	return "unknown"
+}
+
 var DefaultRegistry Registry = NewRegistry()
 
 // Call the given function for each registered metric.
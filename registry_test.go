@@ -1,6 +1,9 @@
 package metrics
 
-import "testing"
+import (
+	"sync"
+	"testing"
+)
 
 func BenchmarkRegistry(b *testing.B) {
 	r := NewRegistry()
@@ -116,3 +119,117 @@ func TestRegistryGetOrRegisterWithLazyInstantiation(t *testing.T) {
 		t.Fatal(i)
 	}
 }
+
+func TestRegistryList(t *testing.T) {
+	r := NewRegistry().(*StandardRegistry)
+	r.Register("counter", NewCounter())
+	r.Register("gauge", NewGauge())
+	r.Register("gaugefloat64", NewGaugeFloat64())
+	r.Register("healthcheck", NewHealthcheck(func(Healthcheck) {}))
+	r.Register("histogram", NewHistogram(NewUniformSample(100)))
+	r.Register("meter", NewMeter())
+	r.Register("timer", NewTimer())
+	r.Describe("timer", "request latency", "ns")
+	infos := r.List()
+	if 7 != len(infos) {
+		t.Fatal(infos)
+	}
+	for _, info := range infos {
+		if info.Name != info.Type {
+			t.Errorf("%s: type %s", info.Name, info.Type)
+		}
+	}
+	if info := infos[6]; "request latency" != info.Description || "ns" != info.Unit {
+		t.Fatal(info)
+	}
+	if info := infos[0]; "" != info.Description || "" != info.Unit {
+		t.Fatal(info)
+	}
+}
+
+func TestRegistryGetOrRegisterOnce(t *testing.T) {
+	r := NewRegistry().(*StandardRegistry)
+	calls := 0
+	factory := func() interface{} {
+		calls++
+		return NewCounter()
+	}
+	metrics := make([]interface{}, 16)
+	wg := &sync.WaitGroup{}
+	wg.Add(len(metrics))
+	for i := range metrics {
+		go func(i int) {
+			defer wg.Done()
+			metrics[i] = r.GetOrRegisterOnce("foo", factory)
+		}(i)
+	}
+	wg.Wait()
+	if 1 != calls {
+		t.Fatal(calls)
+	}
+	for _, m := range metrics {
+		if m != r.Get("foo") {
+			t.Fatal(m)
+		}
+	}
+}
+
+func TestRegistryGetOrRegisterOnceUnregistrable(t *testing.T) {
+	r := NewRegistry().(*StandardRegistry)
+	calls := 0
+	factory := func() interface{} {
+		calls++
+		return "not a metric"
+	}
+	r.GetOrRegisterOnce("foo", factory)
+	if m := r.GetOrRegisterOnce("foo", factory); "not a metric" != m {
+		t.Fatal(m)
+	}
+	if 2 != calls {
+		t.Fatal(calls)
+	}
+	if m := r.Get("foo"); nil != m {
+		t.Fatal(m)
+	}
+}
+
+func TestRegistryRename(t *testing.T) {
+	r := NewRegistry().(*StandardRegistry)
+	NewRegisteredCounter("foo", r).Inc(47)
+	NewRegisteredGauge("baz", r)
+	if err := r.Rename("foo", "bar"); nil != err {
+		t.Fatal(err)
+	}
+	if m := r.Get("foo"); nil != m {
+		t.Fatal(m)
+	}
+	if count := r.Get("bar").(Counter).Count(); 47 != count {
+		t.Fatal(count)
+	}
+	if err := r.Rename("bar", "baz"); DuplicateMetric("baz") != err {
+		t.Fatal(err)
+	}
+	if err := r.Rename("foo", "quux"); UnknownMetric("foo") != err {
+		t.Fatal(err)
+	}
+	if _, ok := r.Get("bar").(Counter); !ok {
+		t.Fatal(r.Get("bar"))
+	}
+}
+
+func TestRegistryClearHistograms(t *testing.T) {
+	r := NewRegistry().(*StandardRegistry)
+	NewRegisteredCounter("counter", r).Inc(47)
+	NewRegisteredHistogram("histogram", r, NewUniformSample(100)).Update(47)
+	NewRegisteredTimer("timer", r).Update(47)
+	r.ClearHistograms()
+	if count := r.Get("histogram").(Histogram).Count(); 0 != count {
+		t.Errorf("histogram: 0 != %v\n", count)
+	}
+	if count := r.Get("counter").(Counter).Count(); 47 != count {
+		t.Errorf("counter: 47 != %v\n", count)
+	}
+	if count := r.Get("timer").(Timer).Count(); 1 != count {
+		t.Errorf("timer: 1 != %v\n", count)
+	}
+}
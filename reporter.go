@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"sync"
+)
+
+// CaptureReport runs reporter against r, capturing what it writes, so that a
+// reporter configuration can be tested against a known registry.  Every
+// built-in reporter has a writer-based entry point to capture: GraphiteOnce,
+// LogOnce, OpenTSDBOnce, WriteJSONOnce, WriteOnce, WritePrometheus,
+// WriteSyslogOnce, and librato's (*Reporter).WriteOnce.
+func CaptureReport(r Registry, reporter func(Registry, io.Writer) error) (string, error) {
+	b := &bytes.Buffer{}
+	err := reporter(r, b)
+	return b.String(), err
+}
+
+// NewTriggeredReporter launches a goroutine which reports r using emit each
+// time trigger is called, complementing the interval-driven reporters for
+// event-driven flushing.  trigger blocks until the report has been emitted;
+// errors are logged.  After stop is called trigger does nothing.
+func NewTriggeredReporter(r Registry, emit func(Registry) error) (trigger func(), stop func()) {
+	return NewTriggeredReporterWithLifecycle(r, emit, nil)
+}
+
+// NewTriggeredReporterWithLifecycle launches a triggered reporter just like
+// NewTriggeredReporter but tracks its goroutine with l, if l is not nil.
+// After l is stopped trigger does nothing, as after stop is called.
+func NewTriggeredReporterWithLifecycle(r Registry, emit func(Registry) error, l *Lifecycle) (trigger func(), stop func()) {
+	requests := make(chan chan struct{})
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	run := func(stopped <-chan struct{}) {
+		defer close(exited)
+		for {
+			select {
+			case req := <-requests:
+				if err := emit(r); nil != err {
+					log.Println(err)
+				}
+				close(req)
+			case <-done:
+				return
+			case <-stopped:
+				return
+			}
+		}
+	}
+	if nil == l {
+		go run(nil)
+	} else {
+		l.Go(run)
+	}
+	trigger = func() {
+		select {
+		case <-done:
+			return
+		case <-exited:
+			return
+		default:
+		}
+		req := make(chan struct{})
+		select {
+		case requests <- req:
+			<-req
+		case <-done:
+		case <-exited:
+		}
+	}
+	var once sync.Once
+	stop = func() {
+		once.Do(func() { close(done) })
+	}
+	return trigger, stop
+}
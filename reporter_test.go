@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"log"
+	"regexp"
+	"testing"
+)
+
+func TestTriggeredReporter(t *testing.T) {
+	r := NewRegistry()
+	c := NewRegisteredCounter("foo", r)
+	var counts []int64
+	trigger, stop := NewTriggeredReporter(r, func(r Registry) error {
+		counts = append(counts, r.Get("foo").(Counter).Count())
+		return nil
+	})
+	c.Inc(1)
+	trigger()
+	c.Inc(1)
+	trigger()
+	stop()
+	trigger()
+	stop()
+	if 2 != len(counts) || 1 != counts[0] || 2 != counts[1] {
+		t.Fatal(counts)
+	}
+}
+
+func TestTriggeredReporterLifecycle(t *testing.T) {
+	r := NewRegistry()
+	l := NewLifecycle()
+	emitted := 0
+	trigger, stop := NewTriggeredReporterWithLifecycle(r, func(Registry) error {
+		emitted++
+		return nil
+	}, l)
+	defer stop()
+	trigger()
+	l.StopAll()
+	trigger()
+	if 1 != emitted {
+		t.Fatalf("emitted: 1 != %v\n", emitted)
+	}
+}
+
+func TestCaptureReport(t *testing.T) {
+	r := NewRegistry()
+	NewRegisteredCounter("foo", r).Inc(47)
+	for _, test := range []struct {
+		name     string
+		reporter func(Registry, io.Writer) error
+		expected string
+	}{
+		{"graphite", func(r Registry, w io.Writer) error {
+			return GraphiteOnce(GraphiteConfig{Registry: r, Prefix: "p"}, w)
+		}, `^p\.foo\.count 47 \d+\n$`},
+		{"json", WriteJSONOnce, `^\{"foo":\{"count":47\}\}\n$`},
+		{"log", func(r Registry, w io.Writer) error {
+			LogOnce(r, log.New(w, "", 0))
+			return nil
+		}, `^counter foo\n  count:              47\n$`},
+		{"opentsdb", func(r Registry, w io.Writer) error {
+			return OpenTSDBOnce(OpenTSDBConfig{Registry: r, Prefix: "p"}, w)
+		}, `^put p\.foo\.count \d+ 47 host=` + regexp.QuoteMeta(getShortHostname()) + `\n$`},
+		{"prometheus", WritePrometheus, `^# TYPE foo gauge\nfoo 47\n$`},
+		{"text", func(r Registry, w io.Writer) error {
+			WriteOnce(r, w)
+			return nil
+		}, `^counter foo\n  count:              47\n$`},
+	} {
+		s, err := CaptureReport(r, test.reporter)
+		if nil != err {
+			t.Errorf("%s: %v\n", test.name, err)
+		}
+		if !regexp.MustCompile(test.expected).MatchString(s) {
+			t.Errorf("%s: %q does not match %q\n", test.name, s, test.expected)
+		}
+	}
+}
+
+func TestCaptureReportError(t *testing.T) {
+	err := errors.New("boom")
+	s, e := CaptureReport(NewRegistry(), func(r Registry, w io.Writer) error {
+		io.WriteString(w, "partial")
+		return err
+	})
+	if "partial" != s || err != e {
+		t.Fatal(s, e)
+	}
+}
@@ -0,0 +1,38 @@
+package metrics
+
+import "time"
+
+// RequestRecorder records everything about a request in one call: a Counter
+// named name.total, a Meter named name.rate, a Histogram of latencies in
+// nanoseconds named name.latency and a Counter of failures named
+// name.errors.
+type RequestRecorder struct {
+	total   Counter
+	rate    Meter
+	latency Histogram
+	errors  Counter
+}
+
+// NewRequestRecorder constructs a new RequestRecorder whose metrics are
+// registered in r under the given name.  Latencies are sampled by s.
+func NewRequestRecorder(r Registry, name string, s Sample) *RequestRecorder {
+	if nil == r {
+		r = DefaultRegistry
+	}
+	return &RequestRecorder{
+		total:   GetOrRegisterCounter(name+".total", r),
+		rate:    GetOrRegisterMeter(name+".rate", r),
+		latency: GetOrRegisterHistogram(name+".latency", r, s),
+		errors:  GetOrRegisterCounter(name+".errors", r),
+	}
+}
+
+// Observe records a request which took d and failed if err is non-nil.
+func (rr *RequestRecorder) Observe(d time.Duration, err error) {
+	rr.total.Inc(1)
+	rr.rate.Mark(1)
+	rr.latency.Update(int64(d))
+	if nil != err {
+		rr.errors.Inc(1)
+	}
+}
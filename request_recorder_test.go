@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRequestRecorder(t *testing.T) {
+	r := NewRegistry()
+	rr := NewRequestRecorder(r, "api", NewUniformSample(100))
+	rr.Observe(10*time.Millisecond, nil)
+	rr.Observe(30*time.Millisecond, errors.New("boom"))
+	if count := r.Get("api.total").(Counter).Count(); 2 != count {
+		t.Errorf("api.total: 2 != %v\n", count)
+	}
+	if count := r.Get("api.rate").(Meter).Count(); 2 != count {
+		t.Errorf("api.rate: 2 != %v\n", count)
+	}
+	h := r.Get("api.latency").(Histogram)
+	if count := h.Count(); 2 != count {
+		t.Errorf("api.latency count: 2 != %v\n", count)
+	}
+	if max := h.Max(); int64(30*time.Millisecond) != max {
+		t.Errorf("api.latency max: 30ms != %v\n", time.Duration(max))
+	}
+	if count := r.Get("api.errors").(Counter).Count(); 1 != count {
+		t.Errorf("api.errors: 1 != %v\n", count)
+	}
+}
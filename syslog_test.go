@@ -0,0 +1,22 @@
+// +build !windows
+
+package metrics
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestCaptureReportSyslog(t *testing.T) {
+	r := NewRegistry()
+	NewRegisteredCounter("foo", r).Inc(47)
+	NewRegisteredGauge("bar", r).Update(1)
+	s, err := CaptureReport(r, WriteSyslogOnce)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if lines := sortedLines(bytes.NewBufferString(s)); !reflect.DeepEqual([]string{"counter foo: count: 47", "gauge bar: value: 1"}, lines) {
+		t.Fatal(lines)
+	}
+}
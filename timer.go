@@ -151,6 +151,12 @@ func (t *StandardTimer) Min() int64 {
 	return t.histogram.Min()
 }
 
+// ObserveSeconds records the duration of an event given in seconds, as is
+// customary in Prometheus instrumentation.
+func (t *StandardTimer) ObserveSeconds(sec float64) {
+	t.Update(time.Duration(sec * float64(time.Second)))
+}
+
 // Percentile returns an arbitrary percentile of the values in the sample.
 func (t *StandardTimer) Percentile(p float64) float64 {
 	return t.histogram.Percentile(p)
@@ -182,7 +188,26 @@ func (t *StandardTimer) RateMean() float64 {
 	return t.meter.RateMean()
 }
 
-// Snapshot returns a read-only copy of the timer.
+// SuspectCoordinatedOmission reports whether more than one percent of the
+// sampled durations exceed expectedInterval, the interval at which events
+// are meant to arrive.  A caller that waits for each event before starting
+// the next stops issuing events while one stalls, so the stall is recorded
+// once instead of once per missed interval and the tail percentiles read
+// lower than the latency callers actually saw.
+func (t *StandardTimer) SuspectCoordinatedOmission(expectedInterval time.Duration) bool {
+	values := t.histogram.Sample().Values()
+	over := 0
+	for _, v := range values {
+		if int64(expectedInterval) < v {
+			over++
+		}
+	}
+	return 100*over > len(values)
+}
+
+// Snapshot returns a read-only copy of the timer.  The histogram and meter
+// are copied under the same lock that Update holds, so the snapshot's count,
+// rates, and percentiles all describe the same point in time.
 func (t *StandardTimer) Snapshot() Timer {
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
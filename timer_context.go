@@ -0,0 +1,19 @@
+package metrics
+
+import "context"
+
+// timerContextKey is the key under which ContextWithTimer stores a Timer.
+type timerContextKey struct{}
+
+// ContextWithTimer returns a copy of ctx carrying t, so middleware can hand
+// a Timer down to the code it ends up calling.
+func ContextWithTimer(ctx context.Context, t Timer) context.Context {
+	return context.WithValue(ctx, timerContextKey{}, t)
+}
+
+// TimerFromContext returns the Timer stored in ctx by ContextWithTimer, if
+// any.
+func TimerFromContext(ctx context.Context) (Timer, bool) {
+	t, ok := ctx.Value(timerContextKey{}).(Timer)
+	return t, ok
+}
@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTimerContext(t *testing.T) {
+	if _, ok := TimerFromContext(context.Background()); ok {
+		t.Fatal("empty context carries a timer")
+	}
+	tm := NewTimer()
+	ctx := ContextWithTimer(context.Background(), tm)
+	got, ok := TimerFromContext(ctx)
+	if !ok || tm != got {
+		t.Fatal(got, ok)
+	}
+	got.Update(47)
+	if count := tm.Count(); 1 != count {
+		t.Errorf("tm.Count(): 1 != %v\n", count)
+	}
+}
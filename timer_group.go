@@ -0,0 +1,29 @@
+package metrics
+
+import "time"
+
+// TimerGroup times the named phases of a multi-stage operation, recording
+// each phase in its own Timer named prefix.name.
+type TimerGroup struct {
+	prefix   string
+	registry Registry
+}
+
+// NewTimerGroup constructs a new TimerGroup whose timers are registered in r
+// under the given prefix.
+func NewTimerGroup(r Registry, prefix string) *TimerGroup {
+	if nil == r {
+		r = DefaultRegistry
+	}
+	return &TimerGroup{prefix: prefix, registry: r}
+}
+
+// Phase starts timing the named phase and returns a function which records
+// its duration when called.  The phase's timer is registered on first use.
+func (g *TimerGroup) Phase(name string) func() {
+	t := GetOrRegisterTimer(g.prefix+"."+name, g.registry)
+	ts := time.Now()
+	return func() {
+		t.UpdateSince(ts)
+	}
+}
@@ -0,0 +1,19 @@
+package metrics
+
+import "testing"
+
+func TestTimerGroup(t *testing.T) {
+	r := NewRegistry()
+	g := NewTimerGroup(r, "pipeline")
+	for i := 0; i < 2; i++ {
+		stop := g.Phase("parse")
+		stop()
+	}
+	g.Phase("render")()
+	if count := r.Get("pipeline.parse").(Timer).Count(); 2 != count {
+		t.Fatalf("pipeline.parse: 2 != %v\n", count)
+	}
+	if count := r.Get("pipeline.render").(Timer).Count(); 1 != count {
+		t.Fatalf("pipeline.render: 1 != %v\n", count)
+	}
+}
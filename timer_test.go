@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"math"
+	"sync"
 	"testing"
 	"time"
 )
@@ -39,6 +40,55 @@ func TestTimerFunc(t *testing.T) {
 	}
 }
 
+func TestTimerObserveSeconds(t *testing.T) {
+	tm := NewTimer().(*StandardTimer)
+	tm.ObserveSeconds(0.25)
+	tm.ObserveSeconds(1.5)
+	tm.ObserveSeconds(2)
+	if count := tm.Count(); 3 != count {
+		t.Errorf("tm.Count(): 3 != %v\n", count)
+	}
+	if median := tm.Percentile(0.5); 1.5e9 != median {
+		t.Errorf("tm.Percentile(0.5): 1.5e9 != %v\n", median)
+	}
+	if min := tm.Min(); 250e6 != min {
+		t.Errorf("tm.Min(): 250e6 != %v\n", min)
+	}
+}
+
+func TestTimerSnapshotConsistent(t *testing.T) {
+	tm := NewTimer()
+	wg := &sync.WaitGroup{}
+	wg.Add(4)
+	for i := 0; i < 4; i++ {
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				tm.Update(time.Duration(i))
+			}
+		}()
+	}
+	done := make(chan bool)
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for {
+		snapshot := tm.Snapshot().(*TimerSnapshot)
+		if hc, mc := snapshot.histogram.Count(), snapshot.meter.Count(); hc != mc {
+			t.Fatalf("histogram count %v != meter count %v\n", hc, mc)
+		}
+		select {
+		case <-done:
+			if count := tm.Snapshot().Count(); 4000 != count {
+				t.Fatalf("tm.Snapshot().Count(): 4000 != %v\n", count)
+			}
+			return
+		default:
+		}
+	}
+}
+
 func TestTimerZero(t *testing.T) {
 	tm := NewTimer()
 	if count := tm.Count(); 0 != count {
@@ -79,3 +129,22 @@ func TestTimerZero(t *testing.T) {
 		t.Errorf("tm.RateMean(): 0.0 != %v\n", rateMean)
 	}
 }
+
+func TestTimerSuspectCoordinatedOmission(t *testing.T) {
+	tm := NewTimer().(*StandardTimer)
+	if tm.SuspectCoordinatedOmission(10 * time.Millisecond) {
+		t.Fatal("empty timer suspected of coordinated omission")
+	}
+	for i := 0; i < 1000; i++ {
+		tm.Update(time.Millisecond)
+	}
+	if tm.SuspectCoordinatedOmission(10 * time.Millisecond) {
+		t.Fatal("steady timer suspected of coordinated omission")
+	}
+	for i := 0; i < 20; i++ {
+		tm.Update(time.Second)
+	}
+	if !tm.SuspectCoordinatedOmission(10 * time.Millisecond) {
+		t.Fatal("stalled timer not suspected of coordinated omission")
+	}
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+)
+
+// MetricTransport is an http.RoundTripper which records the latency and
+// rate of outbound requests in a Timer named prefix.key.requests and the
+// rate of failed requests, those which return an error or a 5xx status, in
+// a Meter named prefix.key.errors.
+type MetricTransport struct {
+	inner    http.RoundTripper
+	registry Registry
+	prefix   string
+
+	// Key returns the name under which to record a request.  It defaults to
+	// the request's host.
+	Key func(*http.Request) string
+}
+
+// NewMetricTransport constructs a new MetricTransport wrapping inner, which
+// defaults to http.DefaultTransport, and registering its metrics in r.
+func NewMetricTransport(inner http.RoundTripper, r Registry, prefix string) *MetricTransport {
+	if nil == inner {
+		inner = http.DefaultTransport
+	}
+	if nil == r {
+		r = DefaultRegistry
+	}
+	return &MetricTransport{
+		inner:    inner,
+		registry: r,
+		prefix:   prefix,
+		Key:      func(req *http.Request) string { return req.URL.Host },
+	}
+}
+
+// RoundTrip executes a single HTTP transaction using the wrapped
+// RoundTripper and records its outcome.
+func (t *MetricTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	name := t.prefix + "." + t.Key(req)
+	ts := time.Now()
+	resp, err := t.inner.RoundTrip(req)
+	GetOrRegisterTimer(name+".requests", t.registry).UpdateSince(ts)
+	if nil != err || 500 <= resp.StatusCode {
+		GetOrRegisterMeter(name+".errors", t.registry).Mark(1)
+	}
+	return resp, err
+}
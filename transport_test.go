@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMetricTransport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if "/fail" == req.URL.Path {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+	host := ts.Listener.Addr().String()
+
+	r := NewRegistry()
+	client := &http.Client{Transport: NewMetricTransport(nil, r, "http")}
+	for _, path := range []string{"/", "/", "/fail"} {
+		resp, err := client.Get(ts.URL + path)
+		if nil != err {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if count := r.Get("http." + host + ".requests").(Timer).Count(); 3 != count {
+		t.Fatalf("requests: 3 != %v\n", count)
+	}
+	if count := r.Get("http." + host + ".errors").(Meter).Count(); 1 != count {
+		t.Fatalf("errors: 1 != %v\n", count)
+	}
+}
+
+func TestMetricTransportError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	ts.Close()
+
+	r := NewRegistry()
+	transport := NewMetricTransport(nil, r, "http")
+	transport.Key = func(req *http.Request) string { return "backend" }
+	client := &http.Client{Transport: transport}
+	if _, err := client.Get(ts.URL); nil == err {
+		t.Fatal("client.Get(): nil error from a closed server")
+	}
+	if count := r.Get("http.backend.requests").(Timer).Count(); 1 != count {
+		t.Fatalf("requests: 1 != %v\n", count)
+	}
+	if count := r.Get("http.backend.errors").(Meter).Count(); 1 != count {
+		t.Fatalf("errors: 1 != %v\n", count)
+	}
+}